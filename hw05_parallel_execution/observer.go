@@ -0,0 +1,60 @@
+package hw05parallelexecution
+
+import "time"
+
+// Observer receives notifications about Run progress.
+// Methods are called from worker goroutines concurrently, so implementation must be goroutine safe.
+type Observer interface {
+	// TaskStarted is called by worker before task execution, task is an index in tasks slice.
+	TaskStarted(worker, task int)
+	// TaskFinished is called by worker after task execution with its duration and returned error.
+	TaskFinished(worker, task int, duration time.Duration, err error)
+	// WorkerIdle is called when worker is ready to receive next task.
+	WorkerIdle(worker int)
+	// LimitExceeded is called once when number of errors reaches maxErrors.
+	LimitExceeded(errs int)
+}
+
+// ObserverFuncs adapts set of functions to Observer, nil functions are skipped.
+type ObserverFuncs struct {
+	OnTaskStarted   func(worker, task int)
+	OnTaskFinished  func(worker, task int, duration time.Duration, err error)
+	OnWorkerIdle    func(worker int)
+	OnLimitExceeded func(errs int)
+}
+
+func (o ObserverFuncs) TaskStarted(worker, task int) {
+	if o.OnTaskStarted != nil {
+		o.OnTaskStarted(worker, task)
+	}
+}
+
+func (o ObserverFuncs) TaskFinished(worker, task int, duration time.Duration, err error) {
+	if o.OnTaskFinished != nil {
+		o.OnTaskFinished(worker, task, duration, err)
+	}
+}
+
+func (o ObserverFuncs) WorkerIdle(worker int) {
+	if o.OnWorkerIdle != nil {
+		o.OnWorkerIdle(worker)
+	}
+}
+
+func (o ObserverFuncs) LimitExceeded(errs int) {
+	if o.OnLimitExceeded != nil {
+		o.OnLimitExceeded(errs)
+	}
+}
+
+type nopObserver struct{}
+
+func (nopObserver) TaskStarted(int, int)                        {}
+func (nopObserver) TaskFinished(int, int, time.Duration, error) {}
+func (nopObserver) WorkerIdle(int)                              {}
+func (nopObserver) LimitExceeded(int)                           {}
+
+var (
+	_ Observer = ObserverFuncs{}
+	_ Observer = nopObserver{}
+)
@@ -0,0 +1,93 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRunObserved(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("all tasks are reported", func(t *testing.T) {
+		tasksCount := 20
+		tasks := make([]Task, 0, tasksCount)
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, task)
+		}
+
+		var started, finished, idle, exceeded int32
+		mu := sync.Mutex{}
+		seen := map[int]bool{}
+		var errs []error
+		var durations []time.Duration
+		observer := ObserverFuncs{
+			OnTaskStarted: func(_, task int) {
+				atomic.AddInt32(&started, 1)
+				mu.Lock()
+				seen[task] = true
+				mu.Unlock()
+			},
+			OnTaskFinished: func(_, _ int, duration time.Duration, err error) {
+				atomic.AddInt32(&finished, 1)
+				// checked by the test goroutine after run
+				mu.Lock()
+				errs = append(errs, err)
+				durations = append(durations, duration)
+				mu.Unlock()
+			},
+			OnWorkerIdle:    func(int) { atomic.AddInt32(&idle, 1) },
+			OnLimitExceeded: func(int) { atomic.AddInt32(&exceeded, 1) },
+		}
+
+		err := RunObserved(tasks, 5, 1, observer)
+		require.NoError(t, err)
+		require.Equal(t, int32(tasksCount), started)
+		require.Equal(t, int32(tasksCount), finished)
+		require.GreaterOrEqual(t, idle, int32(tasksCount))
+		require.Equal(t, int32(0), exceeded)
+		require.Len(t, seen, tasksCount)
+		for i := range errs {
+			require.NoError(t, errs[i])
+			require.GreaterOrEqual(t, int64(durations[i]), int64(0))
+		}
+	})
+
+	t.Run("limit exceeded is reported once", func(t *testing.T) {
+		tasksCount := 30
+		tasks := make([]Task, 0, tasksCount)
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, errTask)
+		}
+
+		var failed, exceeded, errsAtLimit int32
+		observer := ObserverFuncs{
+			OnTaskFinished: func(_, _ int, _ time.Duration, err error) {
+				if err != nil {
+					atomic.AddInt32(&failed, 1)
+				}
+			},
+			OnLimitExceeded: func(errs int) {
+				atomic.AddInt32(&exceeded, 1)
+				atomic.StoreInt32(&errsAtLimit, int32(errs))
+			},
+		}
+
+		maxErrors := 3
+		err := RunObserved(tasks, 4, maxErrors, observer)
+		require.True(t, errors.Is(err, ErrErrorsLimitExceeded))
+		require.Equal(t, int32(1), exceeded)
+		require.Equal(t, int32(maxErrors), errsAtLimit)
+		require.GreaterOrEqual(t, failed, int32(maxErrors))
+	})
+
+	t.Run("nil observer", func(t *testing.T) {
+		err := RunObserved([]Task{task, task}, 2, 0, nil)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,146 @@
+package hw05parallelexecution
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are upper bounds (in seconds) of task duration histogram.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusObserver collects Run metrics and exports them in Prometheus text format.
+type PrometheusObserver struct {
+	namespace string
+	buckets   []float64
+
+	mu            sync.Mutex
+	started       uint64
+	finished      uint64
+	failed        uint64
+	idle          uint64
+	limitExceeded uint64
+	inProgress    int64
+	bucketCounts  []uint64
+	durationSum   float64
+}
+
+// NewPrometheusObserver creates observer, metric names are prefixed with namespace if it is not empty.
+// If buckets are not given, DefaultBuckets are used.
+func NewPrometheusObserver(namespace string, buckets ...float64) *PrometheusObserver {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+	return &PrometheusObserver{
+		namespace:    namespace,
+		buckets:      b,
+		bucketCounts: make([]uint64, len(b)),
+	}
+}
+
+func (p *PrometheusObserver) TaskStarted(int, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.started++
+	p.inProgress++
+}
+
+func (p *PrometheusObserver) TaskFinished(_, _ int, duration time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finished++
+	p.inProgress--
+	if err != nil {
+		p.failed++
+	}
+	sec := duration.Seconds()
+	p.durationSum += sec
+	for i, le := range p.buckets {
+		if sec <= le {
+			p.bucketCounts[i]++
+		}
+	}
+}
+
+func (p *PrometheusObserver) WorkerIdle(int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idle++
+}
+
+func (p *PrometheusObserver) LimitExceeded(int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limitExceeded++
+}
+
+// WriteTo writes current metrics values in Prometheus text exposition format.
+func (p *PrometheusObserver) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	buf := &bytes.Buffer{}
+	p.writeMetric(buf, "tasks_started_total", "counter", "Number of started tasks.", float64(p.started))
+	p.writeMetric(buf, "tasks_finished_total", "counter", "Number of finished tasks.", float64(p.finished))
+	p.writeMetric(buf, "tasks_failed_total", "counter", "Number of tasks finished with error.", float64(p.failed))
+	p.writeMetric(buf, "tasks_in_progress", "gauge", "Number of tasks being executed.", float64(p.inProgress))
+	p.writeMetric(buf, "worker_idle_total", "counter", "Number of times workers became idle.", float64(p.idle))
+	p.writeMetric(
+		buf,
+		"errors_limit_exceeded_total",
+		"counter",
+		"Number of runs stopped by errors limit.",
+		float64(p.limitExceeded),
+	)
+	p.writeHistogram(buf)
+	p.mu.Unlock()
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP allows to use observer as metrics endpoint.
+func (p *PrometheusObserver) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+func (p *PrometheusObserver) writeMetric(buf *bytes.Buffer, name, typ, help string, val float64) {
+	name = p.name(name)
+	fmt.Fprintf(buf, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, typ)
+	fmt.Fprintf(buf, "%s %s\n", name, formatFloat(val))
+}
+
+func (p *PrometheusObserver) writeHistogram(buf *bytes.Buffer) {
+	name := p.name("task_duration_seconds")
+	fmt.Fprintf(buf, "# HELP %s Task execution duration in seconds.\n", name)
+	fmt.Fprintf(buf, "# TYPE %s histogram\n", name)
+	for i, le := range p.buckets {
+		fmt.Fprintf(buf, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(le), p.bucketCounts[i])
+	}
+	fmt.Fprintf(buf, "%s_bucket{le=\"+Inf\"} %d\n", name, p.finished)
+	fmt.Fprintf(buf, "%s_sum %s\n", name, formatFloat(p.durationSum))
+	fmt.Fprintf(buf, "%s_count %d\n", name, p.finished)
+}
+
+func (p *PrometheusObserver) name(name string) string {
+	if p.namespace == "" {
+		return name
+	}
+	return p.namespace + "_" + name
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	_ Observer     = (*PrometheusObserver)(nil)
+	_ io.WriterTo  = (*PrometheusObserver)(nil)
+	_ http.Handler = (*PrometheusObserver)(nil)
+)
@@ -0,0 +1,73 @@
+package hw05parallelexecution
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusObserver(t *testing.T) {
+	t.Run("text format", func(t *testing.T) {
+		p := NewPrometheusObserver("batch", 0.1, 1)
+		p.WorkerIdle(0)
+		p.TaskStarted(0, 0)
+		p.TaskFinished(0, 0, 50*time.Millisecond, nil)
+		p.TaskStarted(0, 1)
+		p.TaskFinished(0, 1, 500*time.Millisecond, errors.New("hzhz"))
+		p.TaskStarted(1, 2)
+		p.TaskFinished(1, 2, 2*time.Second, nil)
+		p.TaskStarted(1, 3)
+		p.LimitExceeded(1)
+
+		buf := &bytes.Buffer{}
+		_, err := p.WriteTo(buf)
+		require.NoError(t, err)
+		out := buf.String()
+
+		for _, line := range []string{
+			"# TYPE batch_tasks_started_total counter",
+			"batch_tasks_started_total 4",
+			"batch_tasks_finished_total 3",
+			"batch_tasks_failed_total 1",
+			"batch_tasks_in_progress 1",
+			"batch_worker_idle_total 1",
+			"batch_errors_limit_exceeded_total 1",
+			"# TYPE batch_task_duration_seconds histogram",
+			`batch_task_duration_seconds_bucket{le="0.1"} 1`,
+			`batch_task_duration_seconds_bucket{le="1"} 2`,
+			`batch_task_duration_seconds_bucket{le="+Inf"} 3`,
+			"batch_task_duration_seconds_sum 2.55",
+			"batch_task_duration_seconds_count 3",
+		} {
+			require.Contains(t, out, line+"\n")
+		}
+	})
+
+	t.Run("default buckets and no namespace", func(t *testing.T) {
+		p := NewPrometheusObserver("")
+		buf := &bytes.Buffer{}
+		_, err := p.WriteTo(buf)
+		require.NoError(t, err)
+		require.Equal(t, len(DefaultBuckets)+1, strings.Count(buf.String(), "task_duration_seconds_bucket"))
+		require.Contains(t, buf.String(), "\ntasks_started_total 0\n")
+	})
+
+	t.Run("http handler", func(t *testing.T) {
+		p := NewPrometheusObserver("batch")
+		err := RunObserved([]Task{task, errTask, task}, 2, 0, p)
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+		require.Contains(t, rec.Body.String(), "batch_tasks_finished_total 3\n")
+		require.Contains(t, rec.Body.String(), "batch_tasks_failed_total 1\n")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"log"
 	"sync"
+	"time"
 )
 
 var (
@@ -15,10 +16,25 @@ var (
 
 type Task func() error
 
+// job binds task to its index in tasks slice.
+type job struct {
+	num  int
+	task Task
+}
+
 var l = log.Default()
 
 // Run starts tasks in maxWorkers goroutines and stops its work when receiving maxErrors errors from tasks.
 func Run(tasks []Task, maxWorkers, maxErrors int) error {
+	return RunObserved(tasks, maxWorkers, maxErrors, nil)
+}
+
+// RunObserved works like Run and reports execution progress to observer.
+// Nil observer is allowed, in this case nothing is reported.
+func RunObserved(tasks []Task, maxWorkers, maxErrors int, observer Observer) error {
+	if observer == nil {
+		observer = nopObserver{}
+	}
 	if maxErrors < 0 {
 		return ErrMaxErrorsLessZero
 	}
@@ -26,7 +42,7 @@ func Run(tasks []Task, maxWorkers, maxErrors int) error {
 		return ErrMaxWorkersLessOne
 	}
 	l.SetOutput(ioutil.Discard) // comment for debug
-	tch := make(chan Task)
+	tch := make(chan job)
 	ech := make(chan error)
 	stop := make(chan bool)
 	wg := sync.WaitGroup{}
@@ -40,10 +56,10 @@ func Run(tasks []Task, maxWorkers, maxErrors int) error {
 	}()
 	for i := 0; i < maxWorkers; i++ {
 		wg.Add(1)
-		go worker(i, &wg, tch, ech, stop)
+		go worker(i, &wg, tch, ech, stop, observer)
 	}
 
-	ret := process(tasks, maxWorkers, maxErrors, tch, ech)
+	ret := process(tasks, maxWorkers, maxErrors, tch, ech, observer)
 
 	for i := 0; i < maxWorkers; i++ {
 		l.Println("Sending stop signal")
@@ -54,7 +70,7 @@ func Run(tasks []Task, maxWorkers, maxErrors int) error {
 }
 
 // main processing of goroutine logic.
-func process(tasks []Task, maxWorkers, maxErrors int, tch chan Task, ech chan error) error {
+func process(tasks []Task, maxWorkers, maxErrors int, tch chan job, ech chan error, observer Observer) error {
 	var (
 		i, done, errs, prc int
 		ret                error
@@ -64,7 +80,7 @@ func process(tasks []Task, maxWorkers, maxErrors int, tch chan Task, ech chan er
 	for {
 		if i < count && ret == nil {
 			select {
-			case tch <- tasks[i]:
+			case tch <- job{num: i, task: tasks[i]}:
 				l.Printf("Writing task: %d\n", i)
 				i++
 				prc++
@@ -82,6 +98,7 @@ func process(tasks []Task, maxWorkers, maxErrors int, tch chan Task, ech chan er
 				count, err = processError(errs, done, maxErrors, maxWorkers, count)
 				if err != nil {
 					ret = err
+					observer.LimitExceeded(errs)
 				}
 			}
 		default:
@@ -108,15 +125,27 @@ func processError(errs int, done int, maxErrors, maxWorkers int, count int) (int
 }
 
 // Starts task to execute, waiting stop signal from stop channel.
-func worker(num int, wg *sync.WaitGroup, tasks <-chan Task, errs chan<- error, stop <-chan bool) {
+func worker(
+	num int,
+	wg *sync.WaitGroup,
+	tasks <-chan job,
+	errs chan<- error,
+	stop <-chan bool,
+	observer Observer,
+) {
 	l.Printf("#%d: starting\n", num)
+	observer.WorkerIdle(num)
 	for {
 		select {
-		case t := <-tasks:
+		case j := <-tasks:
 			l.Printf("#%d: starting task\n", num)
-			err := t()
+			observer.TaskStarted(num, j.num)
+			start := time.Now()
+			err := j.task()
+			observer.TaskFinished(num, j.num, time.Since(start), err)
 			l.Printf("#%d: writing in error channel\n", num)
 			errs <- err
+			observer.WorkerIdle(num)
 		case <-stop:
 			l.Printf("#%d: receiving stop signal\n", num)
 			wg.Done()
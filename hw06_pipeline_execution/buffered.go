@@ -0,0 +1,163 @@
+package hw06pipelineexecution
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy defines behaviour of stage when its output buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until next stage reads value (backpressure).
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop discards value if there is no place in the buffer.
+	OverflowDrop
+)
+
+// BufferedStage describes stage with its output buffer.
+type BufferedStage struct {
+	Name       string
+	Stage      Stage
+	BufferSize int
+	Overflow   OverflowPolicy
+}
+
+// StageStats is a snapshot of stage metrics.
+type StageStats struct {
+	Name string
+	// Processed is a number of values sent to the next stage.
+	Processed uint64
+	// Dropped is a number of values discarded by OverflowDrop policy.
+	Dropped uint64
+	// QueueDepth is a number of values waiting in the output buffer.
+	QueueDepth int
+	// Capacity is a size of the output buffer.
+	Capacity int
+	// Throughput is a number of processed values per second.
+	Throughput float64
+	// Blocked is a time spent waiting for the next stage to read value.
+	Blocked time.Duration
+	// Draining is true after cancellation while stage has not closed its output yet.
+	// Stage which stays in this state for a long time is stalled.
+	Draining bool
+	// Finished is true when stage is closed.
+	Finished bool
+}
+
+// Monitor gives access to metrics of running buffered pipeline.
+type Monitor struct {
+	start  time.Time
+	meters []*stageMeter
+}
+
+// Stats returns metrics of stages in order of pipeline.
+// Slow stage usually has a full buffer before it and an empty one after.
+func (m *Monitor) Stats() []StageStats {
+	stats := make([]StageStats, 0, len(m.meters))
+	for _, meter := range m.meters {
+		stats = append(stats, meter.stats(m.start))
+	}
+	return stats
+}
+
+type stageMeter struct {
+	name       string
+	out        Bi
+	processed  uint64
+	dropped    uint64
+	blocked    int64
+	draining   int32
+	finishedAt int64
+}
+
+func (s *stageMeter) stats(start time.Time) StageStats {
+	end := time.Now()
+	finishedAt := atomic.LoadInt64(&s.finishedAt)
+	if finishedAt > 0 {
+		end = time.Unix(0, finishedAt)
+	}
+	processed := atomic.LoadUint64(&s.processed)
+	st := StageStats{
+		Name:       s.name,
+		Processed:  processed,
+		Dropped:    atomic.LoadUint64(&s.dropped),
+		QueueDepth: len(s.out),
+		Capacity:   cap(s.out),
+		Blocked:    time.Duration(atomic.LoadInt64(&s.blocked)),
+		Draining:   atomic.LoadInt32(&s.draining) == 1,
+		Finished:   finishedAt > 0,
+	}
+	if elapsed := end.Sub(start).Seconds(); elapsed > 0 {
+		st.Throughput = float64(processed) / elapsed
+	}
+	return st
+}
+
+// ExecuteBufferedPipeline creates pipeline from stages with configurable output buffers.
+func ExecuteBufferedPipeline(in In, done In, stages ...BufferedStage) (Out, *Monitor) {
+	m := &Monitor{start: time.Now()}
+	stageCh := in
+	for _, stage := range stages {
+		meter := &stageMeter{name: stage.Name}
+		m.meters = append(m.meters, meter)
+		stageCh = runBufferedStage(done, stageCh, stage, meter)
+	}
+	return stageCh, m
+}
+
+// runBufferedStage starts stage execution asynchronously and collects its metrics.
+func runBufferedStage(done In, in In, stage BufferedStage, meter *stageMeter) Out {
+	outCh := make(Bi, stage.BufferSize)
+	meter.out = outCh
+	stageCh := stage.Stage(in)
+	go func() {
+		defer func() {
+			atomic.StoreInt32(&meter.draining, 1)
+			for range stageCh {
+			}
+			atomic.StoreInt32(&meter.draining, 0)
+			atomic.StoreInt64(&meter.finishedAt, time.Now().UnixNano())
+		}()
+		defer close(outCh)
+		for {
+			select {
+			case v, ok := <-stageCh:
+				if !ok {
+					return
+				}
+				if !meter.send(done, outCh, v, stage.Overflow) {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return outCh
+}
+
+// send writes value to out according to policy, it returns false if done is received.
+func (s *stageMeter) send(done In, out Bi, v interface{}, policy OverflowPolicy) bool {
+	select {
+	case out <- v:
+		atomic.AddUint64(&s.processed, 1)
+		return true
+	default:
+	}
+	if policy == OverflowDrop {
+		atomic.AddUint64(&s.dropped, 1)
+		return true
+	}
+	start := time.Now()
+	defer func() {
+		atomic.AddInt64(&s.blocked, int64(time.Since(start)))
+	}()
+	select {
+	case out <- v:
+		atomic.AddUint64(&s.processed, 1)
+		return true
+	case <-done:
+		return false
+	}
+}
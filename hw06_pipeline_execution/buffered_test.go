@@ -0,0 +1,114 @@
+package hw06pipelineexecution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteBufferedPipeline(t *testing.T) {
+	pass := func(sleep time.Duration) Stage {
+		return func(in In) Out {
+			out := make(Bi)
+			go func() {
+				defer close(out)
+				for v := range in {
+					time.Sleep(sleep)
+					out <- v
+				}
+			}()
+			return out
+		}
+	}
+	data := make([]int, 20)
+	for i := range data {
+		data[i] = i
+	}
+
+	t.Run("buffer does not change result", func(t *testing.T) {
+		out, m := ExecuteBufferedPipeline(generate(data...), nil,
+			BufferedStage{Name: "first", Stage: pass(0), BufferSize: 5},
+			BufferedStage{Name: "second", Stage: pass(0)},
+		)
+		result := collect(out)
+		require.Len(t, result, len(data))
+		for i, v := range result {
+			require.Equal(t, i, v)
+		}
+
+		stats := m.Stats()
+		require.Len(t, stats, 2)
+		require.Equal(t, "first", stats[0].Name)
+		require.Equal(t, 5, stats[0].Capacity)
+		require.Equal(t, 0, stats[1].Capacity)
+		for _, st := range stats {
+			require.Equal(t, uint64(len(data)), st.Processed)
+			require.Zero(t, st.Dropped)
+			require.True(t, st.Finished)
+			require.False(t, st.Draining)
+			require.Greater(t, st.Throughput, float64(0))
+		}
+	})
+
+	t.Run("slow stage fills queue before it", func(t *testing.T) {
+		out, m := ExecuteBufferedPipeline(generate(data...), nil,
+			BufferedStage{Name: "fast", Stage: pass(0), BufferSize: 5},
+			BufferedStage{Name: "slow", Stage: pass(10 * time.Millisecond), BufferSize: 5},
+		)
+		require.Eventually(t, func() bool {
+			stats := m.Stats()
+			return stats[0].QueueDepth == stats[0].Capacity
+		}, time.Second, time.Millisecond)
+		collect(out)
+
+		stats := m.Stats()
+		require.Greater(t, stats[0].Blocked, stats[1].Blocked)
+	})
+
+	t.Run("drop on overflow", func(t *testing.T) {
+		out, m := ExecuteBufferedPipeline(generate(data...), nil,
+			BufferedStage{Name: "dropping", Stage: pass(0), BufferSize: 2, Overflow: OverflowDrop},
+		)
+		// let the stage overflow its buffer before reading
+		require.Eventually(t, func() bool {
+			return m.Stats()[0].Finished
+		}, time.Second, time.Millisecond)
+		result := collect(out)
+
+		stats := m.Stats()[0]
+		require.Len(t, result, 2)
+		require.Equal(t, uint64(2), stats.Processed)
+		require.Equal(t, uint64(len(data)-2), stats.Dropped)
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		done := make(Bi)
+		abortDur := sleepPerStage * 2
+		go func() {
+			<-time.After(abortDur)
+			close(done)
+		}()
+
+		start := time.Now()
+		out, m := ExecuteBufferedPipeline(generate(1, 2, 3, 4, 5), done,
+			BufferedStage{Stage: pass(sleepPerStage), BufferSize: 3},
+			BufferedStage{Stage: pass(sleepPerStage), BufferSize: 3},
+			BufferedStage{Stage: pass(sleepPerStage), BufferSize: 3},
+			BufferedStage{Stage: pass(sleepPerStage), BufferSize: 3},
+		)
+		result := collect(out)
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
+		require.Eventually(t, func() bool {
+			for _, st := range m.Stats() {
+				if !st.Finished {
+					return false
+				}
+			}
+			return true
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,22 @@
+package hw06pipelineexecution
+
+import "time"
+
+// Clock is a source of time for combinators, it allows to replace real time in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// RealClock is a Clock based on time package.
+var RealClock Clock = realClock{}
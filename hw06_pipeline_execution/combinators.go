@@ -0,0 +1,219 @@
+package hw06pipelineexecution
+
+import "time"
+
+// Batch groups values into []interface{} of size values.
+// Incomplete batch is sent when timeout passed since its first value or when input is closed.
+func Batch(done In, clock Clock, size int, timeout time.Duration) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			var (
+				batch []interface{}
+				timer <-chan time.Time
+			)
+			flush := func() bool {
+				if len(batch) == 0 {
+					return true
+				}
+				b := batch
+				batch, timer = nil, nil
+				return send(done, out, interface{}(b))
+			}
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						flush()
+						return
+					}
+					batch = append(batch, v)
+					if len(batch) == 1 {
+						timer = clock.After(timeout)
+					}
+					if len(batch) >= size && !flush() {
+						return
+					}
+				case <-timer:
+					if !flush() {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// TumblingWindow groups values received during consecutive non-overlapping periods of size duration.
+// Every window is sent as []interface{}, empty windows are skipped.
+func TumblingWindow(done In, clock Clock, size time.Duration) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			var window []interface{}
+			tick := clock.After(size)
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						if len(window) > 0 {
+							send(done, out, interface{}(window))
+						}
+						return
+					}
+					window = append(window, v)
+				case <-tick:
+					tick = clock.After(size)
+					if len(window) == 0 {
+						continue
+					}
+					w := window
+					window = nil
+					if !send(done, out, interface{}(w)) {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+type timedValue struct {
+	at time.Time
+	v  interface{}
+}
+
+// SlidingWindow sends every slide duration values received during the last size duration as []interface{}.
+// Windows without values are skipped.
+func SlidingWindow(done In, clock Clock, size, slide time.Duration) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			var (
+				values []timedValue
+				fresh  bool // there are values which were not sent yet
+			)
+			window := func() []interface{} {
+				w := make([]interface{}, 0, len(values))
+				for _, tv := range values {
+					w = append(w, tv.v)
+				}
+				return w
+			}
+			tick := clock.After(slide)
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						if fresh {
+							send(done, out, interface{}(window()))
+						}
+						return
+					}
+					values = append(values, timedValue{at: clock.Now(), v: v})
+					fresh = true
+				case now := <-tick:
+					tick = clock.After(slide)
+					from := now.Add(-size)
+					i := 0
+					for i < len(values) && !values[i].at.After(from) {
+						i++
+					}
+					values = values[i:]
+					if len(values) == 0 {
+						continue
+					}
+					fresh = false
+					if !send(done, out, interface{}(window())) {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// Throttle limits rate of values to perSecond values per second, non-positive perSecond disables limit.
+func Throttle(done In, clock Clock, perSecond int) Stage {
+	var interval time.Duration
+	if perSecond > 0 {
+		interval = time.Second / time.Duration(perSecond)
+	}
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			var last time.Time
+			for {
+				var (
+					v  interface{}
+					ok bool
+				)
+				select {
+				case v, ok = <-in:
+					if !ok {
+						return
+					}
+				case <-done:
+					return
+				}
+				if !last.IsZero() {
+					if wait := interval - clock.Now().Sub(last); wait > 0 {
+						select {
+						case <-clock.After(wait):
+						case <-done:
+							return
+						}
+					}
+				}
+				if !send(done, out, v) {
+					return
+				}
+				last = clock.Now()
+			}
+		}()
+		return out
+	}
+}
+
+// Dedupe skips values which key was already seen, key must return comparable value.
+func Dedupe(done In, key func(v interface{}) interface{}) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			seen := map[interface{}]struct{}{}
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					k := key(v)
+					if _, ok := seen[k]; ok {
+						continue
+					}
+					seen[k] = struct{}{}
+					if !send(done, out, v) {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
@@ -0,0 +1,215 @@
+package hw06pipelineexecution
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// fakeClock moves forward only by Advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	calls   int
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+}
+
+// waitCalls blocks until clock methods are called n times in total.
+func (c *fakeClock) waitCalls(t *testing.T, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.calls >= n
+	}, time.Second, time.Millisecond)
+}
+
+func requireNothing(t *testing.T, out Out) {
+	t.Helper()
+	select {
+	case v := <-out:
+		require.Failf(t, "unexpected value", "%v", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestBatch(t *testing.T) {
+	clock := newFakeClock()
+	in := make(Bi)
+	out := ExecutePipeline(in, nil, Batch(nil, clock, 3, time.Second))
+
+	in <- 1
+	in <- 2
+	in <- 3
+	require.Equal(t, []interface{}{1, 2, 3}, <-out)
+
+	in <- 4
+	clock.waitCalls(t, 2)
+	requireNothing(t, out)
+	clock.Advance(time.Second)
+	require.Equal(t, []interface{}{4}, <-out)
+
+	in <- 5
+	close(in)
+	require.Equal(t, []interface{}{5}, <-out)
+	_, ok := <-out
+	require.False(t, ok)
+}
+
+func TestTumblingWindow(t *testing.T) {
+	clock := newFakeClock()
+	in := make(Bi)
+	out := ExecutePipeline(in, nil, TumblingWindow(nil, clock, time.Second))
+
+	clock.waitCalls(t, 1)
+	in <- 1
+	in <- 2
+	clock.Advance(time.Second)
+	require.Equal(t, []interface{}{1, 2}, <-out)
+
+	clock.waitCalls(t, 2)
+	clock.Advance(time.Second) // empty window is skipped
+	clock.waitCalls(t, 3)
+	requireNothing(t, out)
+
+	in <- 3
+	close(in)
+	require.Equal(t, []interface{}{3}, <-out)
+	_, ok := <-out
+	require.False(t, ok)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	clock := newFakeClock()
+	in := make(Bi)
+	out := ExecutePipeline(in, nil, SlidingWindow(nil, clock, 3*time.Second, time.Second))
+
+	clock.waitCalls(t, 1)
+	in <- 1
+	clock.waitCalls(t, 2)
+	clock.Advance(time.Second)
+	require.Equal(t, []interface{}{1}, <-out)
+
+	clock.waitCalls(t, 3)
+	in <- 2
+	clock.waitCalls(t, 4)
+	clock.Advance(time.Second)
+	require.Equal(t, []interface{}{1, 2}, <-out)
+
+	clock.waitCalls(t, 5)
+	clock.Advance(time.Second)
+	require.Equal(t, []interface{}{2}, <-out)
+
+	clock.waitCalls(t, 6)
+	clock.Advance(time.Second) // all values are out of window
+	clock.waitCalls(t, 7)
+	requireNothing(t, out)
+
+	in <- 3
+	close(in)
+	require.Equal(t, []interface{}{3}, <-out)
+	_, ok := <-out
+	require.False(t, ok)
+}
+
+func TestThrottle(t *testing.T) {
+	clock := newFakeClock()
+	out := ExecutePipeline(generate(1, 2, 3), nil, Throttle(nil, clock, 2))
+
+	require.Equal(t, 1, <-out)
+	clock.waitCalls(t, 3)
+	requireNothing(t, out)
+	clock.Advance(400 * time.Millisecond)
+	requireNothing(t, out)
+	clock.Advance(100 * time.Millisecond)
+	require.Equal(t, 2, <-out)
+
+	clock.waitCalls(t, 6)
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, 3, <-out)
+	_, ok := <-out
+	require.False(t, ok)
+}
+
+func TestDedupe(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	out := ExecutePipeline(
+		generate(1, 2, 3, 4, 5, 6),
+		nil,
+		func(in In) Out {
+			res := make(Bi)
+			go func() {
+				defer close(res)
+				for v := range in {
+					res <- user{ID: v.(int) % 3, Name: "user"}
+				}
+			}()
+			return res
+		},
+		Dedupe(nil, func(v interface{}) interface{} { return v.(user).ID }),
+	)
+	require.Equal(t, []interface{}{user{1, "user"}, user{2, "user"}, user{0, "user"}}, collect(out))
+}
+
+func TestCombinatorsDone(t *testing.T) {
+	clock := newFakeClock()
+	done := make(Bi)
+	in := make(Bi)
+	stages := []Stage{
+		Batch(done, clock, 10, time.Hour),
+		TumblingWindow(done, clock, time.Hour),
+		SlidingWindow(done, clock, time.Hour, time.Hour),
+		Throttle(done, clock, 1),
+		Dedupe(done, func(v interface{}) interface{} { return v }),
+	}
+	out := ExecutePipeline(in, done, stages...)
+	in <- 1
+	close(done)
+	_, ok := <-out
+	require.False(t, ok)
+}
@@ -0,0 +1,221 @@
+package hw06pipelineexecution
+
+import "sync"
+
+// ErrStage processes single value of error-aware pipeline.
+// Returned error stops the whole pipeline.
+type ErrStage func(v interface{}) (interface{}, error)
+
+// Result is an item passed between steps of error-aware pipeline.
+type Result struct {
+	Value interface{}
+	Err   error
+	seq   uint64 // position of value in pipeline input, used to restore order
+}
+
+// Step connects ErrStage to error-aware pipeline.
+type Step func(done In, in <-chan Result) <-chan Result
+
+// FanOutStep runs stage in several workers, every worker writes to its own channel.
+type FanOutStep func(done In, in <-chan Result) []<-chan Result
+
+// ExecuteErrPipeline creates error-aware pipeline from steps.
+// The first error returned by any stage cancels the pipeline and is sent to the error channel.
+// Both channels are closed when pipeline is finished, so error should be read after out is drained.
+func ExecuteErrPipeline(in In, done In, steps ...Step) (Out, <-chan error) {
+	cancel := make(Bi)
+	once := sync.Once{}
+	stop := func() {
+		once.Do(func() { close(cancel) })
+	}
+	go func() {
+		select {
+		case <-done:
+			stop()
+		case <-cancel:
+		}
+	}()
+
+	stepCh := source(cancel, in)
+	for _, step := range steps {
+		stepCh = step(cancel, stepCh)
+	}
+
+	outCh := make(Bi)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		defer close(outCh)
+		defer stop()
+		failed := false
+		for r := range stepCh {
+			if failed {
+				continue // waiting for steps to be finished
+			}
+			if r.Err != nil {
+				failed = true
+				errCh <- r.Err
+				stop()
+				continue
+			}
+			select {
+			case outCh <- r.Value:
+			case <-cancel:
+			}
+		}
+	}()
+	return outCh, errCh
+}
+
+// Map runs stage in a single worker.
+func Map(stage ErrStage) Step {
+	return func(done In, in <-chan Result) <-chan Result {
+		out := make(chan Result)
+		go func() {
+			defer close(out)
+			work(done, in, out, stage)
+		}()
+		return out
+	}
+}
+
+// FanOut runs stage in n parallel workers reading the same input.
+// Its output should be merged by FanIn.
+func FanOut(stage ErrStage, n int) FanOutStep {
+	if n < 1 {
+		n = 1
+	}
+	return func(done In, in <-chan Result) []<-chan Result {
+		outs := make([]<-chan Result, 0, n)
+		for i := 0; i < n; i++ {
+			out := make(chan Result)
+			go func() {
+				defer close(out)
+				work(done, in, out, stage)
+			}()
+			outs = append(outs, out)
+		}
+		return outs
+	}
+}
+
+// FanIn merges outputs of workers to single step.
+// If ordered is true, values are sent in order of pipeline input.
+func FanIn(fanOut FanOutStep, ordered bool) Step {
+	return func(done In, in <-chan Result) <-chan Result {
+		merged := merge(done, fanOut(done, in))
+		if !ordered {
+			return merged
+		}
+		return reorder(done, merged)
+	}
+}
+
+// source numbers input values.
+func source(done In, in In) <-chan Result {
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		var seq uint64
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				if !send(done, out, Result{Value: v, seq: seq}) {
+					return
+				}
+				seq++
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// work applies stage to values from in until in is closed or done is received.
+// Errors are passed through without processing.
+func work(done In, in <-chan Result, out chan<- Result, stage ErrStage) {
+	for {
+		select {
+		case r, ok := <-in:
+			if !ok {
+				return
+			}
+			if r.Err == nil {
+				r.Value, r.Err = stage(r.Value)
+			}
+			if !send(done, out, r) {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// merge sends values from all channels to one channel.
+func merge(done In, ins []<-chan Result) <-chan Result {
+	out := make(chan Result)
+	wg := sync.WaitGroup{}
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func(in <-chan Result) {
+			defer wg.Done()
+			for r := range in {
+				if !send(done, out, r) {
+					return
+				}
+			}
+		}(in)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// reorder restores input order of values using their sequence numbers.
+// Errors are sent immediately because they stop the pipeline anyway.
+func reorder(done In, in <-chan Result) <-chan Result {
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		var next uint64
+		pending := map[uint64]Result{}
+		for r := range in {
+			if r.Err != nil {
+				if !send(done, out, r) {
+					return
+				}
+				continue
+			}
+			pending[r.seq] = r
+			for {
+				p, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				if !send(done, out, p) {
+					return
+				}
+				next++
+			}
+		}
+	}()
+	return out
+}
+
+// send writes value to out, it returns false if done is received first.
+func send[T any](done In, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,152 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errStage = errors.New("stage error")
+
+func generate(data ...int) Bi {
+	in := make(Bi)
+	go func() {
+		defer close(in)
+		for _, v := range data {
+			in <- v
+		}
+	}()
+	return in
+}
+
+func collect(out Out) []interface{} {
+	result := []interface{}{}
+	for v := range out {
+		result = append(result, v)
+	}
+	return result
+}
+
+func TestExecuteErrPipeline(t *testing.T) {
+	sleepy := func(f func(v interface{}) (interface{}, error)) ErrStage {
+		return func(v interface{}) (interface{}, error) {
+			time.Sleep(sleepPerStage)
+			return f(v)
+		}
+	}
+	double := func(v interface{}) (interface{}, error) { return v.(int) * 2, nil }
+	stringify := func(v interface{}) (interface{}, error) { return strconv.Itoa(v.(int)), nil }
+
+	t.Run("simple case", func(t *testing.T) {
+		out, errCh := ExecuteErrPipeline(generate(1, 2, 3, 4, 5), nil, Map(sleepy(double)), Map(sleepy(stringify)))
+		require.Equal(t, []interface{}{"2", "4", "6", "8", "10"}, collect(out))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("error cancels pipeline", func(t *testing.T) {
+		var processed int32
+		failing := func(v interface{}) (interface{}, error) {
+			atomic.AddInt32(&processed, 1)
+			if v.(int) == 3 {
+				return nil, errStage
+			}
+			return v, nil
+		}
+		data := make([]int, 100)
+		for i := range data {
+			data[i] = i
+		}
+		out, errCh := ExecuteErrPipeline(generate(data...), nil, Map(failing), Map(sleepy(double)))
+		result := collect(out)
+		require.ErrorIs(t, <-errCh, errStage)
+		require.LessOrEqual(t, len(result), 3)
+		require.Less(t, atomic.LoadInt32(&processed), int32(len(data)))
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		done := make(Bi)
+		abortDur := sleepPerStage * 2
+		go func() {
+			<-time.After(abortDur)
+			close(done)
+		}()
+		start := time.Now()
+		out, errCh := ExecuteErrPipeline(generate(1, 2, 3, 4, 5), done, Map(sleepy(double)), Map(sleepy(double)),
+			Map(sleepy(double)), Map(sleepy(stringify)))
+		result := collect(out)
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.NoError(t, <-errCh)
+		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
+	})
+}
+
+func TestFanOutFanIn(t *testing.T) {
+	data := make([]int, 20)
+	for i := range data {
+		data[i] = i
+	}
+	random := func(v interface{}) (interface{}, error) {
+		time.Sleep(time.Millisecond * time.Duration(rand.Intn(20)))
+		return v.(int) * 2, nil
+	}
+
+	t.Run("ordered", func(t *testing.T) {
+		out, errCh := ExecuteErrPipeline(generate(data...), nil, FanIn(FanOut(random, 5), true))
+		result := collect(out)
+		require.NoError(t, <-errCh)
+		require.Len(t, result, len(data))
+		for i, v := range result {
+			require.Equal(t, i*2, v)
+		}
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		out, errCh := ExecuteErrPipeline(generate(data...), nil, FanIn(FanOut(random, 5), false))
+		result := collect(out)
+		require.NoError(t, <-errCh)
+		ints := make([]int, 0, len(result))
+		for _, v := range result {
+			ints = append(ints, v.(int))
+		}
+		sort.Ints(ints)
+		for i, v := range ints {
+			require.Equal(t, i*2, v)
+		}
+	})
+
+	t.Run("workers run in parallel", func(t *testing.T) {
+		slow := func(v interface{}) (interface{}, error) {
+			time.Sleep(sleepPerStage)
+			return v, nil
+		}
+		workers := 5
+		start := time.Now()
+		out, errCh := ExecuteErrPipeline(generate(data[:workers]...), nil, FanIn(FanOut(slow, workers), true))
+		result := collect(out)
+		elapsed := time.Since(start)
+		require.NoError(t, <-errCh)
+		require.Len(t, result, workers)
+		require.Less(t, int64(elapsed), int64(sleepPerStage)+int64(fault))
+	})
+
+	t.Run("error in worker", func(t *testing.T) {
+		failing := func(v interface{}) (interface{}, error) {
+			if v.(int) == 10 {
+				return nil, errStage
+			}
+			return random(v)
+		}
+		out, errCh := ExecuteErrPipeline(generate(data...), nil, FanIn(FanOut(failing, 3), true), Map(random))
+		result := collect(out)
+		require.ErrorIs(t, <-errCh, errStage)
+		require.Less(t, len(result), len(data))
+	})
+}
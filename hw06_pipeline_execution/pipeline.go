@@ -21,17 +21,29 @@ func ExecutePipeline(in In, done In, stages ...Stage) Out {
 
 // runStage starts stage execution asynchronously.
 // It returns channel to connect next stage.
+// Done is also awaited while stage prepares the next value, so pipeline is stopped without delay,
+// and output of stage is drained after cancellation, so stage goroutine is not blocked forever.
 func runStage(done In, in In, stage Stage) Out {
 	outCh := make(Bi)
 	go func() {
+		stageCh := stage(in)
 		defer func() {
-			close(outCh)
-			for range outCh {
+			for range stageCh {
 			}
 		}()
-		for v := range stage(in) {
+		defer close(outCh)
+		for {
 			select {
-			case outCh <- v:
+			case v, ok := <-stageCh:
+				if !ok {
+					return
+				}
+				select {
+				case outCh <- v:
+				case <-done:
+					fmt.Println("Done!")
+					return
+				}
 			case <-done:
 				fmt.Println("Done!")
 				return
@@ -110,4 +110,38 @@ func TestPipeline(t *testing.T) {
 		require.Len(t, result, 0)
 		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
 	})
+
+	t.Run("stage is drained after done", func(t *testing.T) {
+		in := make(Bi)
+		done := make(Bi)
+		finished := make(chan struct{})
+		stage := func(in In) Out {
+			out := make(Bi)
+			go func() {
+				defer close(finished)
+				defer close(out)
+				for v := range in {
+					out <- v
+				}
+			}()
+			return out
+		}
+
+		out := runStage(done, in, stage)
+		in <- 1
+		close(done)
+		go func() {
+			// stage is still sending values nobody reads
+			in <- 2
+			close(in)
+		}()
+		for range out {
+		}
+
+		select {
+		case <-finished:
+		case <-time.After(time.Second):
+			require.Fail(t, "stage goroutine is blocked after done")
+		}
+	})
 }
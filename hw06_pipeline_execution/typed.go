@@ -0,0 +1,79 @@
+package hw06pipelineexecution
+
+import "context"
+
+// TypedStage is a stage of typed pipeline.
+// Stage must close its output channel when input is closed or context is cancelled,
+// otherwise pipeline can not guarantee that all goroutines are finished.
+type TypedStage[I, O any] func(ctx context.Context, in <-chan I) <-chan O
+
+// ExecuteTypedPipeline runs stage (possibly built by Chain) asynchronously and returns its output.
+// Output is closed when stage is finished or context is cancelled.
+func ExecuteTypedPipeline[I, O any](ctx context.Context, in <-chan I, stage TypedStage[I, O]) <-chan O {
+	return runTypedStage(ctx, in, stage)
+}
+
+// Chain connects output of the first stage to input of the second one.
+func Chain[I, M, O any](first TypedStage[I, M], second TypedStage[M, O]) TypedStage[I, O] {
+	return func(ctx context.Context, in <-chan I) <-chan O {
+		return second(ctx, runTypedStage(ctx, in, first))
+	}
+}
+
+// Transform creates stage applying f to every value.
+func Transform[I, O any](f func(I) O) TypedStage[I, O] {
+	return func(ctx context.Context, in <-chan I) <-chan O {
+		out := make(chan O)
+		go func() {
+			defer close(out)
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					select {
+					case out <- f(v):
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// runTypedStage starts stage and forwards its output until context is cancelled.
+func runTypedStage[I, O any](ctx context.Context, in <-chan I, stage TypedStage[I, O]) <-chan O {
+	outCh := make(chan O)
+	go forward(ctx.Done(), stage(ctx, in), outCh)
+	return outCh
+}
+
+// forward sends values from src to dst until src is closed or done is received, then it closes dst.
+func forward[T, D any](done <-chan D, src <-chan T, dst chan<- T) {
+	defer func() {
+		// lets stage goroutine finish its work after cancellation
+		for range src {
+		}
+	}()
+	defer close(dst)
+	for {
+		select {
+		case v, ok := <-src:
+			if !ok {
+				return
+			}
+			select {
+			case dst <- v:
+			case <-done:
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
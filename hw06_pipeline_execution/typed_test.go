@@ -0,0 +1,89 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func generateTyped(ctx context.Context, data ...int) <-chan int {
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for _, v := range data {
+			select {
+			case in <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return in
+}
+
+func TestExecuteTypedPipeline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	sleepy := func(f func(int) int) TypedStage[int, int] {
+		return Transform(func(v int) int {
+			time.Sleep(sleepPerStage)
+			return f(v)
+		})
+	}
+	stages := Chain(
+		Chain(
+			Chain(sleepy(func(v int) int { return v }), sleepy(func(v int) int { return v * 2 })),
+			sleepy(func(v int) int { return v + 100 }),
+		),
+		Transform(func(v int) string {
+			time.Sleep(sleepPerStage)
+			return strconv.Itoa(v)
+		}),
+	)
+
+	t.Run("simple case", func(t *testing.T) {
+		ctx := context.Background()
+		data := []int{1, 2, 3, 4, 5}
+
+		result := make([]string, 0, 10)
+		start := time.Now()
+		for s := range ExecuteTypedPipeline(ctx, generateTyped(ctx, data...), stages) {
+			result = append(result, s)
+		}
+		elapsed := time.Since(start)
+
+		require.Equal(t, []string{"102", "104", "106", "108", "110"}, result)
+		require.Less(t, int64(elapsed), int64(sleepPerStage)*int64(4+len(data)-1)+int64(fault))
+	})
+
+	t.Run("cancel case", func(t *testing.T) {
+		abortDur := sleepPerStage * 2
+		ctx, cancel := context.WithTimeout(context.Background(), abortDur)
+		defer cancel()
+
+		result := make([]string, 0, 10)
+		start := time.Now()
+		for s := range ExecuteTypedPipeline(ctx, generateTyped(ctx, 1, 2, 3, 4, 5), stages) {
+			result = append(result, s)
+		}
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
+		require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	})
+
+	t.Run("consumer stops reading", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		data := make([]int, 100)
+		out := ExecuteTypedPipeline(ctx, generateTyped(ctx, data...), Transform(func(v int) int { return v + 1 }))
+		require.Equal(t, 1, <-out)
+		cancel()
+		for range out {
+		}
+	})
+}
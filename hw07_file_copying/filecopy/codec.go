@@ -0,0 +1,233 @@
+package filecopy
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+)
+
+// DefaultCodec is used for compression if codec name is not set.
+const DefaultCodec = "gzip"
+
+// Codec compresses and decompresses data streams.
+// Other formats (e.g. zstd) can be added by RegisterCodec.
+type Codec interface {
+	// Name is used to select codec by WithCompress and WithDecompress.
+	Name() string
+	// Magic is a prefix of compressed stream used for auto-detection, nil if format can not be detected.
+	Magic() []byte
+	// NewWriter creates writer compressing data to w, Close should flush the stream.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader creates reader decompressing data from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{}
+)
+
+func init() {
+	RegisterCodec(gzipCodec{})
+}
+
+// RegisterCodec adds codec to registry, codec with the same name is replaced.
+func RegisterCodec(codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[codec.Name()] = codec
+}
+
+// LookupCodec returns registered codec by name.
+func LookupCodec(name string) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+	}
+	return codec, nil
+}
+
+// DetectCodec returns registered codec which magic bytes are a prefix of header.
+// The longest magic wins if several codecs match.
+func DetectCodec(header []byte) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	var found Codec
+	for _, codec := range codecs {
+		magic := codec.Magic()
+		if len(magic) == 0 || !bytes.HasPrefix(header, magic) {
+			continue
+		}
+		if found == nil || len(magic) > len(found.Magic()) {
+			found = codec
+		}
+	}
+	if found == nil {
+		return nil, ErrUnknownCodec
+	}
+	return found, nil
+}
+
+// maxMagicLen returns length of the longest registered magic.
+func maxMagicLen() int {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	n := 0
+	for _, codec := range codecs {
+		if len(codec.Magic()) > n {
+			n = len(codec.Magic())
+		}
+	}
+	return n
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string {
+	return "gzip"
+}
+
+func (gzipCodec) Magic() []byte {
+	return []byte{0x1f, 0x8b}
+}
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// transcoding reports if data is compressed or decompressed while copying.
+func (c *copier) transcoding() bool {
+	return c.compress || c.decompress
+}
+
+// checkCodecOptions validates compression settings before copying.
+// Output of codec does not match source, so it can not be resumed or checked by source sidecar file.
+func (c *copier) checkCodecOptions() error {
+	if !c.transcoding() {
+		return nil
+	}
+	if (c.compress && c.decompress) || c.resume || c.recursive || c.sidecar != SidecarNone {
+		return ErrCodecConflict
+	}
+	if c.codec == "" {
+		return nil
+	}
+	_, err := LookupCodec(c.codec)
+	return err
+}
+
+// codecWriter returns writer compressing or decompressing data to w.
+// Data written to it is source data, so copying progress tracks source bytes.
+func (c *copier) codecWriter(w io.Writer) (io.WriteCloser, error) {
+	var codec Codec
+	if c.codec != "" {
+		var err error
+		if codec, err = LookupCodec(c.codec); err != nil {
+			return nil, err
+		}
+	}
+	if c.compress {
+		if codec == nil {
+			codec, _ = LookupCodec(DefaultCodec)
+		}
+		return codec.NewWriter(w)
+	}
+	return newDecompressWriter(codec, w), nil
+}
+
+// copyTranscoded copies the rest of range through codec.
+// If verification is enabled, output of codec is hashed instead of source.
+func (c *copier) copyTranscoded(r io.Reader, dst *destination, limit int64, h hash.Hash) <-chan progress {
+	out := dst.writer()
+	if c.sparse && dst.regular() {
+		out = &sparseWriter{fp: dst.fp}
+	}
+	if h != nil {
+		out = io.MultiWriter(out, h)
+	}
+	cw, err := c.codecWriter(out)
+	if err != nil {
+		progressCh := make(chan progress, 1)
+		progressCh <- progress{error: err}
+		close(progressCh)
+		return progressCh
+	}
+	return closeAfter(c.copyAsync(r, cw, limit), cw)
+}
+
+// closeAfter forwards progress and closes w when copying is finished.
+func closeAfter(progressCh <-chan progress, w io.Closer) <-chan progress {
+	out := make(chan progress)
+	go func() {
+		defer close(out)
+		failed := false
+		for prg := range progressCh {
+			failed = failed || prg.error != nil
+			out <- prg
+		}
+		if err := w.Close(); err != nil && !failed {
+			out <- progress{error: err}
+		}
+	}()
+	return out
+}
+
+// decompressWriter decompresses written data to w in separate goroutine.
+// Format is detected by magic bytes if codec is nil.
+type decompressWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func newDecompressWriter(codec Codec, w io.Writer) *decompressWriter {
+	pr, pw := io.Pipe()
+	d := &decompressWriter{pw: pw, done: make(chan error, 1)}
+	go func() {
+		err := decompress(codec, pr, w)
+		// unblocks writer if decompression is failed
+		pr.CloseWithError(err)
+		d.done <- err
+	}()
+	return d
+}
+
+func decompress(codec Codec, r io.Reader, w io.Writer) error {
+	if codec == nil {
+		br := bufio.NewReader(r)
+		header, err := br.Peek(maxMagicLen())
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if codec, err = DetectCodec(header); err != nil {
+			return err
+		}
+		r = br
+	}
+	zr, err := codec.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	_, err = io.Copy(w, zr)
+	return err
+}
+
+func (d *decompressWriter) Write(p []byte) (int, error) {
+	return d.pw.Write(p)
+}
+
+// Close waits for decompression of written data.
+func (d *decompressWriter) Close() error {
+	_ = d.pw.Close()
+	return <-d.done
+}
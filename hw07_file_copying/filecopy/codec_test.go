@@ -0,0 +1,226 @@
+package filecopy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// xorCodec is a test codec inverting bits of data after magic header.
+type xorCodec struct{}
+
+func (xorCodec) Name() string {
+	return "xor"
+}
+
+func (xorCodec) Magic() []byte {
+	return []byte("XOR1")
+}
+
+func (c xorCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if _, err := w.Write(c.Magic()); err != nil {
+		return nil, err
+	}
+	return xorWriter{w}, nil
+}
+
+func (c xorCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	header := make([]byte, len(c.Magic()))
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(xorReader{r}), nil
+}
+
+type xorWriter struct {
+	w io.Writer
+}
+
+func (x xorWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	for i, b := range p {
+		buf[i] = ^b
+	}
+	return x.w.Write(buf)
+}
+
+func (xorWriter) Close() error {
+	return nil
+}
+
+type xorReader struct {
+	r io.Reader
+}
+
+func (x xorReader) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	for i := range p[:n] {
+		p[i] = ^p[i]
+	}
+	return n, err
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func gunzipFile(t *testing.T, path string) []byte {
+	t.Helper()
+	fp, err := os.Open(path)
+	require.NoError(t, err)
+	defer fp.Close()
+	zr, err := gzip.NewReader(fp)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return data
+}
+
+func TestCompress(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+
+	t.Run("window", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.gz")
+		p := &recordingProgress{}
+		err := Copy("../testdata/input.txt", to, 100, 1000, WithCompress(""), WithProgress(p))
+		require.NoError(t, err)
+		require.Equal(t, input[100:1100], gunzipFile(t, to))
+		require.Equal(t, int64(1000), p.tasks[0].Total)
+		require.Equal(t, int64(1000), p.copied())
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.gz")
+		err := Copy("../testdata/input.txt", to, 0, 0, WithCompress("gzip"), WithVerify(ChecksumSHA256))
+		require.NoError(t, err)
+		require.Equal(t, input, gunzipFile(t, to))
+	})
+
+	t.Run("parallel and sparse are ignored", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.gz")
+		err := Copy("../testdata/input.txt", to, 0, 0, WithCompress(""), WithParallel(4, 100), WithSparse())
+		require.NoError(t, err)
+		require.Equal(t, input, gunzipFile(t, to))
+	})
+}
+
+func TestDecompress(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+	header := []byte("10 bytes: ")
+	compressed := append(append([]byte{}, header...), gzipData(t, input)...)
+
+	dir := t.TempDir()
+	from := filepath.Join(dir, "in.bin")
+	require.NoError(t, os.WriteFile(from, compressed, 0o644))
+
+	for _, name := range []string{"", "gzip"} {
+		name := name
+		t.Run("codec "+name, func(t *testing.T) {
+			to := filepath.Join(t.TempDir(), "out.txt")
+			p := &recordingProgress{}
+			err := Copy(from, to, int64(len(header)), 0, WithDecompress(name), WithProgress(p))
+			require.NoError(t, err)
+			res, err := os.ReadFile(to)
+			require.NoError(t, err)
+			require.Equal(t, input, res)
+			require.Equal(t, int64(len(compressed)-len(header)), p.copied())
+		})
+	}
+
+	t.Run("reader to writer", func(t *testing.T) {
+		dst := &memFile{}
+		err := CopyContext(context.Background(), FromReader(bytes.NewReader(compressed), int64(len(compressed))),
+			ToWriter(dst), WithOffset(int64(len(header))), WithDecompress(""), WithVerify(ChecksumCRC32C))
+		require.NoError(t, err)
+		require.Equal(t, input, dst.data)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		err := Copy(from, to, 0, 0, WithDecompress(""))
+		require.ErrorIs(t, err, ErrUnknownCodec)
+		require.NoFileExists(t, to)
+		require.NoFileExists(t, to+partSuffix)
+	})
+
+	t.Run("corrupted stream", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		err := Copy(from, to, int64(len(header)), 100, WithDecompress("gzip"))
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		require.NoFileExists(t, to)
+	})
+}
+
+func TestCodecRegistry(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	RegisterCodec(xorCodec{})
+	defer func() {
+		codecsMu.Lock()
+		delete(codecs, xorCodec{}.Name())
+		codecsMu.Unlock()
+	}()
+
+	codec, err := DetectCodec([]byte("XOR1 data"))
+	require.NoError(t, err)
+	require.Equal(t, "xor", codec.Name())
+	codec, err = DetectCodec([]byte{0x1f, 0x8b, 8})
+	require.NoError(t, err)
+	require.Equal(t, "gzip", codec.Name())
+	_, err = DetectCodec([]byte("XO"))
+	require.ErrorIs(t, err, ErrUnknownCodec)
+	_, err = LookupCodec("zstd")
+	require.ErrorIs(t, err, ErrUnknownCodec)
+
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	compressed := filepath.Join(dir, "out.xor")
+	require.NoError(t, Copy("../testdata/input.txt", compressed, 0, 0, WithCompress("xor")))
+	data, err := os.ReadFile(compressed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("XOR1"), data[:4])
+
+	decompressed := filepath.Join(dir, "out.txt")
+	require.NoError(t, Copy(compressed, decompressed, 0, 0, WithDecompress("")))
+	data, err = os.ReadFile(decompressed)
+	require.NoError(t, err)
+	require.Equal(t, input, data)
+}
+
+func TestCodecErrors(t *testing.T) {
+	to := filepath.Join(t.TempDir(), "out")
+	cases := []struct {
+		name     string
+		opts     []Option
+		expected error
+	}{
+		{"unknown codec", []Option{WithCompress("zstd")}, ErrUnknownCodec},
+		{"both directions", []Option{WithCompress(""), WithDecompress("")}, ErrCodecConflict},
+		{"resume", []Option{WithCompress(""), WithResume()}, ErrCodecConflict},
+		{"sidecar", []Option{WithDecompress(""), WithSidecar(SidecarWrite)}, ErrCodecConflict},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := Copy("../testdata/input.txt", to, 0, 0, tc.opts...)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
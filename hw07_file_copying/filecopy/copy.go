@@ -0,0 +1,351 @@
+// Package filecopy copies files, directories and readers with offset and limit,
+// resuming, verification and sparse files support.
+package filecopy
+
+import (
+	"context"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+const (
+	// BufferSize is a size of buffer used when zero-copy is not available, it is a minimal chunk size as well.
+	BufferSize = 1 << 20
+	// MaxChunkSize limits amount of data copied between progress checks.
+	MaxChunkSize = 64 << 20
+	// ProgressInterval is a minimal interval between progress updates.
+	ProgressInterval = 100 * time.Millisecond
+
+	chunkDuration = 50 * time.Millisecond
+)
+
+type copier struct {
+	ctx      context.Context
+	fromPath string
+	toPath   string
+	reader   io.ReaderAt // source if fromPath is empty
+	size     int64       // size of reader
+	writer   io.WriterAt // destination if toPath is empty
+	offset   int64
+	limit    int64
+	resume   bool
+	progress Progress
+
+	compress   bool
+	decompress bool
+	codec      string // codec name, empty for default one or for detection
+
+	parallel  int // number of workers copying chunks, 0 for sequential copying
+	chunkSize int64
+
+	recursive bool
+	workers   int
+	symlinks  SymlinkPolicy
+	include   []string
+	exclude   []string
+
+	sparse      bool
+	verify      string // checksum algorithm, empty if verification is disabled
+	sidecar     SidecarMode
+	expectedSum string // checksum read from source sidecar file
+}
+
+type progress struct {
+	add   int64
+	error error
+}
+
+// Copy copies limit bytes (whole file if limit is 0) from fromPath starting at offset to toPath.
+func Copy(fromPath, toPath string, offset, limit int64, opts ...Option) error {
+	opts = append([]Option{WithOffset(offset), WithLimit(limit)}, opts...)
+	return CopyContext(context.Background(), FromPath(fromPath), ToPath(toPath), opts...)
+}
+
+// CopyContext copies src to dst. Copying is stopped when ctx is cancelled,
+// temporary file is removed then unless resume is enabled.
+func CopyContext(ctx context.Context, src Source, dst Destination, opts ...Option) error {
+	if src.path == "" && src.reader == nil {
+		return ErrEmptySourcePath
+	}
+	if dst.path == "" && dst.writer == nil {
+		return ErrEmptyDestinationPath
+	}
+	co := newCopier(ctx, src, dst, opts...)
+	if co.offset < 0 {
+		return ErrInvalidOffset
+	}
+	if co.limit < 0 {
+		return ErrInvalidLimit
+	}
+	return co.execute()
+}
+
+func newCopier(ctx context.Context, src Source, dst Destination, opts ...Option) *copier {
+	co := &copier{
+		ctx:      ctx,
+		fromPath: src.path,
+		reader:   src.reader,
+		size:     src.size,
+		toPath:   dst.path,
+		writer:   dst.writer,
+		progress: nopProgress{},
+	}
+	for _, opt := range opts {
+		opt(co)
+	}
+	return co
+}
+
+func (c *copier) execute() error {
+	if err := c.checkVerifyOptions(); err != nil {
+		return err
+	}
+	if err := c.checkCodecOptions(); err != nil {
+		return err
+	}
+	size := c.size
+	if c.reader == nil {
+		fileInfo, err := c.statSource()
+		if os.IsNotExist(err) {
+			return NewCopyError(c.fromPath, ErrFileNotExists)
+		}
+		if err != nil {
+			return err
+		}
+		if fileInfo.IsDir() {
+			if !c.recursive {
+				return NewCopyError(c.fromPath, ErrIsDirectory)
+			}
+			return c.copyDir()
+		}
+		size = sourceSize(fileInfo)
+	}
+	if size != UnknownSize && c.offset > size {
+		return NewCopyError(c.fromPath, ErrOffsetExceedsFileSize)
+	}
+	if c.resume && (size == UnknownSize || c.writer != nil || c.toPath == StdioPath) {
+		return NewCopyError(c.fromPath, ErrUnsupportedFile)
+	}
+	if c.sidecar == SidecarCheck {
+		if err := c.readSidecar(); err != nil {
+			return err
+		}
+	}
+
+	if c.writer == nil && c.toPath != StdioPath {
+		fileInfoTo, err := os.Stat(c.toPath)
+		if err == nil && fileInfoTo.IsDir() {
+			c.toPath = c.toPath + string(os.PathSeparator) + path.Base(c.fromPath)
+		}
+	}
+	total := c.calculateTotal(size)
+	c.progress.Start(Task{From: c.fromPath, To: c.toPath, Total: total})
+	defer c.progress.Finish()
+	return c.copyFile(total)
+}
+
+// copyFile copies data to temporary file placed near destination and renames it on success.
+// In resume mode previously written temporary file is continued if its content matches source.
+// If verification is enabled, checksum of source is calculated while copying and compared
+// with checksum of written data before renaming.
+func (c *copier) copyFile(total int64) error {
+	rdFp, ra, err := c.openSource()
+	if err != nil {
+		return NewCopyError(c.fromPath, err)
+	}
+	if rdFp != nil && rdFp != os.Stdin {
+		defer rdFp.Close()
+	}
+
+	dst, err := c.openDestination(ra, total)
+	if err != nil {
+		return err
+	}
+
+	r, err := c.seekSource(rdFp, ra, c.offset+dst.written)
+	if err != nil {
+		dst.abort(c.resume)
+		return NewCopyError(c.fromPath, err)
+	}
+
+	var srcHash hash.Hash
+	if c.verify != "" {
+		srcHash, _ = newHash(c.verify)
+		if err := c.hashWritten(srcHash, ra, dst.written); err != nil {
+			dst.abort(c.resume)
+			return NewCopyError(c.fromPath, err)
+		}
+		if !c.transcoding() {
+			// hashing reader disables zero-copy, but data is read from source only once
+			r = io.TeeReader(r, srcHash)
+		}
+	}
+
+	limit := c.limit
+	if limit > 0 {
+		limit -= dst.written
+	}
+	if dst.written > 0 {
+		c.progress.Add(dst.written)
+	}
+	if c.limit == 0 || limit > 0 {
+		parallel := c.parallelAllowed(rdFp, dst, total)
+		var progressCh <-chan progress
+		if parallel {
+			progressCh = c.copyParallel(ra, dst, total)
+		} else {
+			progressCh = c.copyData(rdFp, r, dst, limit, total, srcHash)
+		}
+		copied, err := c.track(progressCh)
+		if err != nil {
+			// only contiguous prefix is kept for resuming
+			dst.truncate(dst.written + copied)
+			dst.abort(c.resume)
+			return NewCopyError(c.toPath, err)
+		}
+		if parallel && srcHash != nil {
+			// source is read by chunks, so its checksum is calculated separately
+			if _, err := io.Copy(srcHash, io.NewSectionReader(ra, c.offset+dst.written, copied)); err != nil {
+				dst.abort(false)
+				return NewCopyError(c.fromPath, err)
+			}
+		}
+		if c.sparse && dst.regular() {
+			if err := (&sparseWriter{fp: dst.fp}).finish(); err != nil {
+				dst.abort(c.resume)
+				return NewCopyError(c.toPath, err)
+			}
+		}
+	}
+	if srcHash == nil {
+		return dst.commit()
+	}
+
+	sum := srcHash.Sum(nil)
+	if err := c.verifyDestination(dst, sum); err != nil {
+		dst.abort(false)
+		return err
+	}
+	if err := dst.commit(); err != nil {
+		return err
+	}
+	if c.sidecar == SidecarWrite {
+		return c.writeSidecar(sum)
+	}
+	return nil
+}
+
+// track passes copied bytes to progress and returns their number and copying error.
+func (c *copier) track(progressCh <-chan progress) (int64, error) {
+	var copied int64
+	var err error
+	for prg := range progressCh {
+		copied += prg.add
+		c.progress.Add(prg.add)
+		if prg.error != nil {
+			err = prg.error
+		}
+	}
+	return copied, err
+}
+
+// copyData starts copying of the rest of range, in sparse mode holes are not written to destination.
+func (c *copier) copyData(
+	src *os.File, r io.Reader, dst *destination, limit, total int64, h hash.Hash,
+) <-chan progress {
+	if c.transcoding() {
+		return c.copyTranscoded(r, dst, limit, h)
+	}
+	if !c.sparse || !dst.regular() {
+		return c.copyAsync(r, dst.writer(), limit)
+	}
+	w := &sparseWriter{fp: dst.fp}
+	if src == nil || total == UnknownSize {
+		return c.copyAsync(r, w, limit)
+	}
+	if info, err := src.Stat(); err != nil || !info.Mode().IsRegular() {
+		return c.copyAsync(r, w, limit)
+	}
+	return c.copySparseAsync(src, r, w, c.offset+dst.written, c.offset+total, h)
+}
+
+// copyAsync copies not more than limit bytes (whole reader if limit is 0) and reports progress.
+// Data is copied by chunks through io.CopyBuffer, so *os.File destination can use
+// copy_file_range/sendfile. Chunk size is adapted to copying speed, progress is reported
+// not more often than ProgressInterval. Context is checked between chunks.
+func (c *copier) copyAsync(r io.Reader, w io.Writer, limit int64) <-chan progress {
+	progressCh := make(chan progress)
+	go func() {
+		defer close(progressCh)
+		buff := make([]byte, BufferSize)
+		chunk := int64(BufferSize)
+		var total, pending int64
+		lastReport := time.Now()
+		for limit == 0 || total < limit {
+			if err := c.ctx.Err(); err != nil {
+				progressCh <- progress{add: pending, error: err}
+				return
+			}
+			n := chunk
+			if limit > 0 && limit-total < n {
+				n = limit - total
+			}
+			start := time.Now()
+			written, err := io.CopyBuffer(w, io.LimitReader(r, n), buff)
+			total += written
+			pending += written
+			if err != nil {
+				progressCh <- progress{add: pending, error: err}
+				return
+			}
+			if written < n {
+				break // EOF
+			}
+			chunk = adaptChunk(chunk, time.Since(start))
+			if time.Since(lastReport) >= ProgressInterval {
+				progressCh <- progress{add: pending}
+				pending = 0
+				lastReport = time.Now()
+			}
+		}
+		if pending > 0 {
+			progressCh <- progress{add: pending}
+		}
+	}()
+	return progressCh
+}
+
+// adaptChunk changes chunk size to copy one chunk in about chunkDuration.
+func adaptChunk(chunk int64, spent time.Duration) int64 {
+	switch {
+	case spent < chunkDuration/2 && chunk < MaxChunkSize:
+		return chunk * 2
+	case spent > chunkDuration*2 && chunk > BufferSize:
+		return chunk / 2
+	}
+	return chunk
+}
+
+// calculateTotal returns number of bytes to copy, it is UnknownSize if source size is unknown and there is no limit.
+func (c *copier) calculateTotal(fileSize int64) int64 {
+	if fileSize == UnknownSize {
+		if c.limit > 0 {
+			return c.limit
+		}
+		return UnknownSize
+	}
+	if c.limit == 0 && c.offset == 0 {
+		return fileSize
+	}
+	total := fileSize
+	total -= c.offset
+	if c.limit > 0 {
+		if total > c.limit {
+			total = c.limit
+		}
+	}
+	return total
+}
@@ -0,0 +1,146 @@
+package filecopy
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const benchFileSize = 32 << 20
+
+// legacyCopyAsync is the previous implementation with 20 bytes buffer and progress per chunk.
+func legacyCopyAsync(r io.Reader, w io.Writer, limit int64) <-chan progress {
+	progressCh := make(chan progress)
+	go func() {
+		defer close(progressCh)
+		buff := make([]byte, 20)
+		var totalWrite, read, write int64
+		for {
+			n, readErr := r.Read(buff)
+			read = int64(n)
+			if read > 0 {
+				if limit > 0 && totalWrite+read > limit {
+					read = limit - totalWrite
+				}
+				n, err := w.Write(buff[:read])
+				if err != nil {
+					progressCh <- progress{error: err}
+					return
+				}
+				write = int64(n)
+				totalWrite += write
+				progressCh <- progress{add: write}
+				if limit > 0 && totalWrite >= limit {
+					break
+				}
+			}
+			if readErr == io.EOF {
+				break
+			}
+			if readErr != nil {
+				progressCh <- progress{error: readErr}
+				return
+			}
+		}
+	}()
+	return progressCh
+}
+
+func createBenchFile(b *testing.B, size int64) string {
+	b.Helper()
+	from := filepath.Join(b.TempDir(), "from")
+	fp, err := os.Create(from)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer fp.Close()
+	if _, err := io.CopyN(fp, rand.Reader, size); err != nil {
+		b.Fatal(err)
+	}
+	return from
+}
+
+func benchmarkCopy(b *testing.B, copyAsync func(r io.Reader, w io.Writer, limit int64) <-chan progress) {
+	b.Helper()
+	from := createBenchFile(b, benchFileSize)
+	to := from + ".copy"
+	b.SetBytes(benchFileSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := os.Open(from)
+		if err != nil {
+			b.Fatal(err)
+		}
+		w, err := os.Create(to)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for prg := range copyAsync(r, w, 0) {
+			if prg.error != nil {
+				b.Fatal(prg.error)
+			}
+		}
+		r.Close()
+		w.Close()
+	}
+}
+
+// go test -run=^$ -bench=Copy -benchtime=3x .
+func BenchmarkCopyLegacy(b *testing.B) {
+	benchmarkCopy(b, legacyCopyAsync)
+}
+
+func BenchmarkCopy(b *testing.B) {
+	c := &copier{ctx: context.Background()}
+	benchmarkCopy(b, c.copyAsync)
+}
+
+func BenchmarkCopyWithoutZeroCopy(b *testing.B) {
+	c := &copier{ctx: context.Background()}
+	benchmarkCopy(b, func(r io.Reader, w io.Writer, limit int64) <-chan progress {
+		// hides ReaderFrom/WriterTo of *os.File
+		return c.copyAsync(struct{ io.Reader }{r}, struct{ io.Writer }{w}, limit)
+	})
+}
+
+// benchmarkCopyFile copies file by CopyContext, so preallocation and renaming are measured too.
+func benchmarkCopyFile(b *testing.B, size int64, opts ...Option) {
+	b.Helper()
+	from := createBenchFile(b, size)
+	to := from + ".copy"
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := CopyContext(context.Background(), FromPath(from), ToPath(to), opts...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCopyFile compares sequential copying of 32MiB and 256MiB files with parallel copying by 2, 4 and 8 workers,
+// with and without verification of checksum. Verification reads source twice in parallel mode.
+//
+// go test -run=^$ -bench=CopyFile -benchtime=3x .
+func BenchmarkCopyFile(b *testing.B) {
+	for _, size := range []int64{benchFileSize, 8 * benchFileSize} {
+		b.Run(fmt.Sprintf("%dMiB/sequential", size>>20), func(b *testing.B) {
+			benchmarkCopyFile(b, size)
+		})
+		b.Run(fmt.Sprintf("%dMiB/sequential-verify", size>>20), func(b *testing.B) {
+			benchmarkCopyFile(b, size, WithVerify(ChecksumCRC32C))
+		})
+		for _, workers := range []int{2, 4, 8} {
+			workers := workers
+			b.Run(fmt.Sprintf("%dMiB/parallel-%d", size>>20, workers), func(b *testing.B) {
+				benchmarkCopyFile(b, size, WithParallel(workers, 0))
+			})
+			b.Run(fmt.Sprintf("%dMiB/parallel-%d-verify", size>>20, workers), func(b *testing.B) {
+				benchmarkCopyFile(b, size, WithParallel(workers, 0), WithVerify(ChecksumCRC32C))
+			})
+		}
+	}
+}
@@ -0,0 +1,549 @@
+package filecopy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestCopy(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	cases := []struct {
+		from     string
+		to       string
+		offset   int64
+		limit    int64
+		expected string
+	}{
+		{"input.txt", "res_offset0_limit0.txt", 0, 0, "out_offset0_limit0.txt"},
+		{"input.txt", "res_offset0_limit10.txt", 0, 10, "out_offset0_limit10.txt"},
+		{"input.txt", "res_offset0_limit1000.txt", 0, 1000, "out_offset0_limit1000.txt"},
+		{"input.txt", "res_offset0_limit10000.txt", 0, 10000, "out_offset0_limit10000.txt"},
+		{"input.txt", "res_offset100_limit1000.txt", 100, 1000, "out_offset100_limit1000.txt"},
+		{"input.txt", "res_offset6000_limit1000.txt", 6000, 1000, "out_offset6000_limit1000.txt"},
+	}
+
+	getHash := func(file string) string {
+		fp, err := os.Open(file)
+		require.NoError(t, err)
+		defer fp.Close()
+
+		h := sha256.New()
+		_, err = io.Copy(h, fp)
+		require.NoError(t, err)
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		title := fmt.Sprintf("%s -> %s", tc.from, tc.to)
+		t.Run(title, func(t *testing.T) {
+			dir := "../testdata/"
+			from := dir + tc.from
+			to := dir + tc.to
+			err := Copy(from, to, tc.offset, tc.limit)
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(to)
+			}()
+			require.Equal(t, getHash(dir+tc.expected), getHash(to))
+
+			fromStat, err := os.Stat(from)
+			require.NoError(t, err)
+
+			toStat, err := os.Stat(to)
+			require.NoError(t, err)
+
+			c := &copier{fromPath: tc.from, toPath: tc.to, limit: tc.limit, offset: tc.offset}
+			require.Equal(t, toStat.Size(), c.calculateTotal(fromStat.Size()))
+		})
+	}
+}
+
+func TestInputErrors(t *testing.T) {
+	cases := []struct {
+		from   string
+		to     string
+		offset int64
+		limit  int64
+		err    error
+	}{
+		{"", "to", 0, 0, ErrEmptySourcePath},
+		{"test", "", 0, 0, ErrEmptyDestinationPath},
+		{"test", "test2", -1, 0, ErrInvalidOffset},
+		{"test", "test2", 1, -1, ErrInvalidLimit},
+		{"", "test2", 1, 1, ErrEmptySourcePath},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			err := Copy(tc.from, tc.to, tc.offset, tc.limit)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestFileErrors(t *testing.T) {
+	cases := []struct {
+		from   string
+		to     string
+		offset int64
+		limit  int64
+		err    error
+	}{
+		{"hzhz", "to", 0, 0, ErrFileNotExists},
+		{"./", "to", 0, 0, ErrIsDirectory},
+		{"../testdata/out_offset0_limit1000.txt", "./", 1001, 0, ErrOffsetExceedsFileSize},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			err := Copy(tc.from, tc.to, tc.offset, tc.limit)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestEmptySource(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "empty.txt")
+	require.NoError(t, os.WriteFile(from, nil, 0o600))
+	to := filepath.Join(dir, "out.txt")
+
+	err := Copy(from, to, 10, 0)
+	require.ErrorIs(t, err, ErrOffsetExceedsFileSize)
+
+	require.NoError(t, Copy(from, to, 0, 0, WithResume()))
+	res, err := os.ReadFile(to)
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	// offset of reader of unknown size is checked while reading
+	err = CopyContext(context.Background(), FromReader(bytes.NewReader([]byte("abc")), UnknownSize),
+		ToWriter(&memFile{}), WithOffset(4))
+	require.ErrorIs(t, err, ErrOffsetExceedsFileSize)
+	out := &memFile{}
+	require.NoError(t, CopyContext(context.Background(), FromReader(bytes.NewReader([]byte("abc")), UnknownSize),
+		ToWriter(out), WithOffset(3)))
+	require.Empty(t, out.data)
+}
+
+func TestResume(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+
+	corrupted := make([]byte, 1000)
+	copy(corrupted, input)
+	corrupted[500] ^= 0xff
+
+	cases := []struct {
+		name    string
+		part    []byte // content of temporary file left by interrupted copying
+		target  []byte // content of destination file left by interrupted copying
+		offset  int64
+		limit   int64
+		expLeft int64 // expected number of bytes to be copied
+	}{
+		{"continue part", input[:3000], nil, 0, 0, int64(len(input) - 3000)},
+		{"continue part with offset and limit", input[100:600], nil, 100, 1000, 500},
+		{"corrupted part", corrupted, nil, 0, 0, int64(len(input))},
+		{"part is bigger than range", input[:2000], nil, 0, 1000, 1000},
+		{"complete part", input[:1000], nil, 0, 1000, 0},
+		{"truncated target", nil, input[:4000], 0, 0, int64(len(input) - 4000)},
+		{"nothing to resume", nil, nil, 6000, 0, int64(len(input) - 6000)},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			to := filepath.Join(t.TempDir(), "out.txt")
+			if tc.part != nil {
+				require.NoError(t, os.WriteFile(to+partSuffix, tc.part, 0o600))
+			}
+			if tc.target != nil {
+				require.NoError(t, os.WriteFile(to, tc.target, 0o600))
+			}
+			p := &recordingProgress{}
+			c := newCopier(context.Background(), FromPath("../testdata/input.txt"), ToPath(to),
+				WithOffset(tc.offset), WithLimit(tc.limit), WithResume(), WithProgress(p))
+			fileInfo, err := os.Stat(c.fromPath)
+			require.NoError(t, err)
+			total := c.calculateTotal(fileInfo.Size())
+			err = c.copyFile(total)
+			require.NoError(t, err)
+			require.Equal(t, total, p.copied())
+			if resumed := total - tc.expLeft; resumed > 0 {
+				require.Equal(t, resumed, p.adds[0])
+			}
+
+			end := int64(len(input))
+			if tc.limit > 0 && tc.offset+tc.limit < end {
+				end = tc.offset + tc.limit
+			}
+			res, err := os.ReadFile(to)
+			require.NoError(t, err)
+			require.Equal(t, input[tc.offset:end], res)
+
+			_, err = os.Stat(to + partSuffix)
+			require.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestTemporaryFile(t *testing.T) {
+	dir := t.TempDir()
+	to := filepath.Join(dir, "out.txt")
+	require.NoError(t, os.WriteFile(to, []byte("previous content"), 0o600))
+
+	t.Run("successful copying replaces destination", func(t *testing.T) {
+		err := Copy("../testdata/input.txt", to, 0, 10)
+		require.NoError(t, err)
+		res, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Len(t, res, 10)
+		_, err = os.Stat(to + partSuffix)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("failed copying keeps destination", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		c := newCopier(ctx, FromPath("../testdata/input.txt"), ToPath(to))
+		err := c.copyFile(100)
+		require.ErrorIs(t, err, context.Canceled)
+		res, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Len(t, res, 10)
+		_, err = os.Stat(to + partSuffix)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("failed copying keeps part in resume mode", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		c := newCopier(ctx, FromPath("../testdata/input.txt"), ToPath(to), WithResume())
+		err := c.copyFile(100)
+		require.ErrorIs(t, err, context.Canceled)
+		_, err = os.Stat(to + partSuffix)
+		require.NoError(t, err)
+	})
+
+	t.Run("failed resuming keeps destination", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(to, []byte("0123456789"), 0o600))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		c := newCopier(ctx, FromPath("../testdata/input.txt"), ToPath(to), WithResume())
+		err := c.copyFile(100)
+		require.ErrorIs(t, err, context.Canceled)
+		res, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, "0123456789", string(res))
+	})
+}
+
+func TestCopyAsync(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	data := make([]byte, 10*BufferSize+123)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	t.Run("progress is throttled", func(t *testing.T) {
+		c := &copier{ctx: context.Background()}
+		w := &bytes.Buffer{}
+		var total int64
+		updates := 0
+		for prg := range c.copyAsync(bytes.NewReader(data), w, 0) {
+			require.NoError(t, prg.error)
+			total += prg.add
+			updates++
+		}
+		require.Equal(t, int64(len(data)), total)
+		require.Equal(t, data, w.Bytes())
+		require.Less(t, updates, 5)
+	})
+
+	t.Run("limit inside chunk", func(t *testing.T) {
+		c := &copier{ctx: context.Background()}
+		w := &bytes.Buffer{}
+		limit := int64(BufferSize + 10)
+		var total int64
+		for prg := range c.copyAsync(bytes.NewReader(data), w, limit) {
+			require.NoError(t, prg.error)
+			total += prg.add
+		}
+		require.Equal(t, limit, total)
+		require.Equal(t, data[:limit], w.Bytes())
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		c := &copier{ctx: context.Background()}
+		errWrite := errors.New("write error")
+		var err error
+		for prg := range c.copyAsync(bytes.NewReader(data), failingWriter{errWrite}, 0) {
+			err = prg.error
+		}
+		require.ErrorIs(t, err, errWrite)
+	})
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestAdaptChunk(t *testing.T) {
+	require.Equal(t, int64(2*BufferSize), adaptChunk(BufferSize, time.Millisecond))
+	require.Equal(t, int64(MaxChunkSize), adaptChunk(MaxChunkSize, time.Millisecond))
+	require.Equal(t, int64(BufferSize), adaptChunk(2*BufferSize, time.Second))
+	require.Equal(t, int64(BufferSize), adaptChunk(BufferSize, time.Second))
+	require.Equal(t, int64(4*BufferSize), adaptChunk(4*BufferSize, chunkDuration))
+}
+
+func TestNonRegularFiles(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+
+	t.Run("device with limit", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out")
+		require.NoError(t, Copy("/dev/urandom", to, 0, 1000))
+		stat, err := os.Stat(to)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), stat.Size())
+	})
+
+	t.Run("device with offset", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out")
+		require.NoError(t, Copy("/dev/zero", to, 100, 50))
+		res, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, make([]byte, 50), res)
+	})
+
+	t.Run("offset exceeds stream length", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		stdin := os.Stdin
+		os.Stdin = r
+		defer func() {
+			os.Stdin = stdin
+			r.Close()
+		}()
+		_, err = w.Write(input[:10])
+		require.NoError(t, err)
+		w.Close()
+
+		to := filepath.Join(t.TempDir(), "out")
+		err = Copy(StdioPath, to, 20, 0)
+		require.ErrorIs(t, err, ErrOffsetExceedsFileSize)
+		_, err = os.Stat(to)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("stdin to stdout", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+		require.NoError(t, err)
+		defer out.Close()
+		stdin, stdout := os.Stdin, os.Stdout
+		os.Stdin, os.Stdout = r, out
+		defer func() {
+			os.Stdin, os.Stdout = stdin, stdout
+			r.Close()
+		}()
+		go func() {
+			defer w.Close()
+			_, _ = w.Write(input)
+		}()
+
+		require.NoError(t, Copy(StdioPath, StdioPath, 100, 1000))
+		res, err := os.ReadFile(out.Name())
+		require.NoError(t, err)
+		require.Equal(t, input[100:1100], res)
+	})
+
+	t.Run("resume is not supported for streams", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out")
+		err := Copy("/dev/zero", to, 0, 10, WithResume())
+		require.ErrorIs(t, err, ErrUnsupportedFile)
+	})
+
+	t.Run("total of unknown size", func(t *testing.T) {
+		require.Equal(t, int64(UnknownSize), (&copier{offset: 10}).calculateTotal(UnknownSize))
+		require.Equal(t, int64(100), (&copier{offset: 10, limit: 100}).calculateTotal(UnknownSize))
+	})
+}
+
+// recordingProgress saves all progress calls.
+type recordingProgress struct {
+	mu       sync.Mutex
+	tasks    []Task
+	adds     []int64
+	finished int
+}
+
+func (p *recordingProgress) Start(task Task) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tasks = append(p.tasks, task)
+}
+
+func (p *recordingProgress) Add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.adds = append(p.adds, n)
+}
+
+func (p *recordingProgress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finished++
+}
+
+func (p *recordingProgress) copied() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var total int64
+	for _, n := range p.adds {
+		total += n
+	}
+	return total
+}
+
+// memFile is in-memory io.ReaderAt and io.WriterAt.
+type memFile struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (m *memFile) ReadAt(p []byte, off int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *memFile) WriteAt(p []byte, off int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if end := off + int64(len(p)); end > int64(len(m.data)) {
+		m.data = append(m.data, make([]byte, end-int64(len(m.data)))...)
+	}
+	return copy(m.data[off:], p), nil
+}
+
+type writerAtOnly struct {
+	io.WriterAt
+}
+
+func TestCopyContext(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("reader to writer", func(t *testing.T) {
+		dst := &memFile{}
+		err := CopyContext(ctx, FromReader(bytes.NewReader(input), int64(len(input))), ToWriter(dst),
+			WithOffset(100), WithLimit(1000), WithVerify(ChecksumSHA256))
+		require.NoError(t, err)
+		require.Equal(t, input[100:1100], dst.data)
+	})
+
+	t.Run("reader of unknown size to file", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		err := CopyContext(ctx, FromReader(bytes.NewReader(input), UnknownSize), ToPath(to), WithOffset(6000))
+		require.NoError(t, err)
+		res, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, input[6000:], res)
+	})
+
+	t.Run("file to writer", func(t *testing.T) {
+		dst := &memFile{}
+		err := CopyContext(ctx, FromPath("../testdata/input.txt"), ToWriter(dst))
+		require.NoError(t, err)
+		require.Equal(t, input, dst.data)
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		p := &recordingProgress{}
+		err := CopyContext(ctx, FromPath("../testdata/input.txt"), ToPath(to), WithLimit(1000), WithProgress(p))
+		require.NoError(t, err)
+		require.Equal(t, []Task{{From: "../testdata/input.txt", To: to, Total: 1000}}, p.tasks)
+		require.Equal(t, int64(1000), p.copied())
+		require.Equal(t, 1, p.finished)
+	})
+
+	t.Run("recursive progress", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "src")
+		makeTree(t, src, map[string]string{"a.txt": "aaa", "sub/b.txt": "bb"})
+		p := &recordingProgress{}
+		err := CopyContext(ctx, FromPath(src), ToPath(filepath.Join(t.TempDir(), "dst")),
+			WithRecursive(2), WithProgress(p))
+		require.NoError(t, err)
+		require.Len(t, p.tasks, 1)
+		require.Equal(t, int64(5), p.tasks[0].Total)
+		require.Equal(t, int64(5), p.copied())
+		require.Equal(t, 1, p.finished)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		dir := t.TempDir()
+		to := filepath.Join(dir, "out.txt")
+		err := CopyContext(cancelled, FromPath("../testdata/input.txt"), ToPath(to))
+		require.ErrorIs(t, err, context.Canceled)
+		require.NoFileExists(t, to)
+		require.NoFileExists(t, to+partSuffix)
+
+		src := filepath.Join(t.TempDir(), "src")
+		makeTree(t, src, map[string]string{"a.txt": "aaa", "sub/b.txt": "bb"})
+		err = CopyContext(cancelled, FromPath(src), ToPath(filepath.Join(dir, "dst")), WithRecursive(2))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := CopyContext(ctx, Source{}, ToWriter(&memFile{}))
+		require.ErrorIs(t, err, ErrEmptySourcePath)
+		err = CopyContext(ctx, FromReader(bytes.NewReader(input), int64(len(input))), Destination{})
+		require.ErrorIs(t, err, ErrEmptyDestinationPath)
+		err = CopyContext(ctx, FromReader(bytes.NewReader(input), int64(len(input))), ToWriter(&memFile{}),
+			WithOffset(-1))
+		require.ErrorIs(t, err, ErrInvalidOffset)
+		err = CopyContext(ctx, FromReader(bytes.NewReader(input), int64(len(input))), ToWriter(&memFile{}),
+			WithOffset(int64(len(input)+1)))
+		require.ErrorIs(t, err, ErrOffsetExceedsFileSize)
+		err = CopyContext(ctx, FromPath("../testdata/input.txt"), ToWriter(&memFile{}), WithResume())
+		require.ErrorIs(t, err, ErrUnsupportedFile)
+		err = CopyContext(ctx, FromPath("../testdata/input.txt"), ToWriter(writerAtOnly{&memFile{}}),
+			WithVerify(ChecksumSHA256))
+		require.ErrorIs(t, err, ErrUnsupportedFile)
+	})
+}
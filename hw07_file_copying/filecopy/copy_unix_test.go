@@ -0,0 +1,76 @@
+//go:build !windows
+// +build !windows
+
+package filecopy
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestCopyFifoWithOffset(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	fifo := filepath.Join(dir, "fifo")
+	require.NoError(t, syscall.Mkfifo(fifo, 0o600))
+	go func() {
+		fp, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer fp.Close()
+		_, _ = fp.Write(input)
+	}()
+	to := filepath.Join(dir, "out")
+	require.NoError(t, Copy(fifo, to, 100, 0))
+	res, err := os.ReadFile(to)
+	require.NoError(t, err)
+	require.Equal(t, input[100:], res)
+}
+
+func TestCopyDestinationAttributes(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+
+	t.Run("symlink is kept", func(t *testing.T) {
+		dir := t.TempDir()
+		target := filepath.Join(dir, "target.txt")
+		link := filepath.Join(dir, "link.txt")
+		require.NoError(t, os.WriteFile(target, []byte("previous content"), 0o600))
+		require.NoError(t, os.Symlink(target, link))
+
+		require.NoError(t, Copy("../testdata/input.txt", link, 0, 10))
+		info, err := os.Lstat(link)
+		require.NoError(t, err)
+		require.NotZero(t, info.Mode()&os.ModeSymlink)
+		res, err := os.ReadFile(target)
+		require.NoError(t, err)
+		require.Equal(t, input[:10], res)
+	})
+
+	t.Run("mode is kept", func(t *testing.T) {
+		for _, resume := range []bool{false, true} {
+			to := filepath.Join(t.TempDir(), "out.txt")
+			require.NoError(t, os.WriteFile(to, input[:10], 0o600))
+			require.NoError(t, os.Chmod(to, 0o640))
+			var opts []Option
+			if resume {
+				opts = append(opts, WithResume())
+			}
+
+			require.NoError(t, Copy("../testdata/input.txt", to, 0, 100, opts...))
+			info, err := os.Stat(to)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+		}
+	})
+}
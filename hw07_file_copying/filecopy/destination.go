@@ -0,0 +1,243 @@
+package filecopy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// partSuffix is appended to destination path to get temporary file name.
+const partSuffix = ".part"
+
+// Destination is a file or writer to copy to.
+type Destination struct {
+	path   string
+	writer io.WriterAt
+}
+
+// ToPath creates destination writing file, StdioPath means stdout.
+// Existing directory means file with source name inside it.
+func ToPath(path string) Destination {
+	return Destination{path: path}
+}
+
+// ToWriter creates destination writing w from its beginning.
+// Verification requires w to implement io.ReaderAt, resuming is not supported.
+func ToWriter(w io.WriterAt) Destination {
+	return Destination{writer: w}
+}
+
+// destination is a file being written, it becomes visible by its final path after commit only.
+type destination struct {
+	fp      *os.File
+	out     *offsetWriter // used if fp is nil
+	path    string
+	tmpPath string // empty if data is written directly to path
+	written int64  // size of already copied data
+	stdout  bool
+}
+
+// openDestination opens temporary file for writing.
+// In resume mode it keeps verified prefix of previously written file.
+func (c *copier) openDestination(src io.ReaderAt, total int64) (*destination, error) {
+	if c.writer != nil {
+		return &destination{out: &offsetWriter{w: c.writer}}, nil
+	}
+	if c.toPath == StdioPath {
+		return &destination{fp: os.Stdout, path: c.toPath, stdout: true}, nil
+	}
+	// symlink is kept, file it points to is replaced
+	path := c.toPath
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	d := &destination{path: path, tmpPath: path + partSuffix}
+
+	perm := os.FileMode(0o666)
+	info, err := os.Stat(path)
+	if err == nil && !info.Mode().IsRegular() {
+		// devices and pipes can not be replaced by rename
+		d.tmpPath = ""
+		d.fp, err = os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return nil, NewCopyError(path, err)
+		}
+		return d, nil
+	}
+	if err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	if !c.resume {
+		d.fp, err = openPart(d.tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm, info != nil)
+		if err != nil {
+			return nil, NewCopyError(d.tmpPath, err)
+		}
+		return d, nil
+	}
+
+	_, err = os.Stat(d.tmpPath)
+	partMissing := os.IsNotExist(err)
+	d.fp, err = openPart(d.tmpPath, os.O_RDWR|os.O_CREATE, perm, info != nil)
+	if err != nil {
+		return nil, NewCopyError(d.tmpPath, err)
+	}
+	if partMissing && info != nil && info.Size() <= total {
+		// destination was left by copying without temporary file, it is kept until commit
+		if err := copyPrefix(path, d.fp); err != nil {
+			d.abort(false)
+			return nil, NewCopyError(path, err)
+		}
+	}
+	if err := c.continueDestination(src, d, total); err != nil {
+		d.fp.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// openPart opens temporary file with permissions perm, they are set explicitly
+// to keep mode of replaced file regardless of umask.
+func openPart(path string, flag int, perm os.FileMode, keepMode bool) (*os.File, error) {
+	fp, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	if keepMode {
+		if err := fp.Chmod(perm); err != nil {
+			fp.Close()
+			return nil, err
+		}
+	}
+	return fp, nil
+}
+
+// copyPrefix copies content of file path to dst.
+func copyPrefix(path string, dst io.Writer) error {
+	fp, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	_, err = io.Copy(dst, fp)
+	return err
+}
+
+// continueDestination checks written prefix and positions file after it, mismatched file is truncated.
+func (c *copier) continueDestination(src io.ReaderAt, d *destination, total int64) error {
+	info, err := d.fp.Stat()
+	if err != nil {
+		return NewCopyError(d.tmpPath, err)
+	}
+	size := info.Size()
+	if size > 0 && size <= total {
+		same, err := samePrefix(io.NewSectionReader(src, c.offset, size), io.NewSectionReader(d.fp, 0, size))
+		if err != nil {
+			return NewCopyError(d.tmpPath, err)
+		}
+		if same {
+			d.written = size
+		}
+	}
+	if err := d.fp.Truncate(d.written); err != nil {
+		return NewCopyError(d.tmpPath, err)
+	}
+	if _, err := d.fp.Seek(d.written, io.SeekStart); err != nil {
+		return NewCopyError(d.tmpPath, err)
+	}
+	return nil
+}
+
+// writer returns writer of destination data.
+func (d *destination) writer() io.Writer {
+	if d.fp == nil {
+		return d.out
+	}
+	return d.fp
+}
+
+// readBack returns reader of data written to destination.
+func (d *destination) readBack() (io.Reader, error) {
+	if d.fp == nil {
+		ra, ok := d.out.w.(io.ReaderAt)
+		if !ok {
+			return nil, ErrUnsupportedFile
+		}
+		return io.NewSectionReader(ra, 0, d.out.off), nil
+	}
+	if err := d.fp.Sync(); err != nil {
+		return nil, err
+	}
+	info, err := d.fp.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return io.NewSectionReader(d.fp, 0, info.Size()), nil
+}
+
+// regular reports if data is written to regular file, which can have holes and be read back.
+func (d *destination) regular() bool {
+	return d.fp != nil && d.tmpPath != ""
+}
+
+// truncate sets size of destination file, it is used to drop data after copied prefix.
+func (d *destination) truncate(size int64) {
+	if d.regular() {
+		_ = d.fp.Truncate(size)
+	}
+}
+
+// commit closes file and moves it to the final path.
+func (d *destination) commit() error {
+	if d.fp == nil || d.stdout {
+		return nil
+	}
+	if err := d.fp.Close(); err != nil {
+		return NewCopyError(d.path, err)
+	}
+	if d.tmpPath == "" {
+		return nil
+	}
+	if err := os.Rename(d.tmpPath, d.path); err != nil {
+		return NewCopyError(d.path, err)
+	}
+	return nil
+}
+
+// abort closes file, temporary file is removed unless it should be kept for resuming.
+func (d *destination) abort(keep bool) {
+	if d.fp == nil || d.stdout {
+		return
+	}
+	_ = d.fp.Close()
+	if d.tmpPath != "" && !keep {
+		_ = os.Remove(d.tmpPath)
+	}
+}
+
+// offsetWriter writes to io.WriterAt sequentially.
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.off)
+	o.off += int64(n)
+	return n, err
+}
+
+// samePrefix compares checksums of two readers.
+func samePrefix(a, b io.Reader) (bool, error) {
+	ha := sha256.New()
+	if _, err := io.Copy(ha, a); err != nil {
+		return false, err
+	}
+	hb := sha256.New()
+	if _, err := io.Copy(hb, b); err != nil {
+		return false, err
+	}
+	return bytes.Equal(ha.Sum(nil), hb.Sum(nil)), nil
+}
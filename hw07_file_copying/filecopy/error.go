@@ -0,0 +1,67 @@
+package filecopy
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+type CopyError struct {
+	Path string
+	Err  error
+}
+
+func (e *CopyError) Error() string {
+	return e.Path + " - " + e.Err.Error()
+}
+
+func (e *CopyError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	_ error           = (*CopyError)(nil)
+	_ xerrors.Wrapper = (*CopyError)(nil)
+)
+
+func NewCopyError(path string, err error) *CopyError {
+	return &CopyError{
+		Path: path,
+		Err:  err,
+	}
+}
+
+// CopyErrors contains failures of files copied in recursive mode.
+type CopyErrors []*CopyError
+
+func (e CopyErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+var _ error = CopyErrors(nil)
+
+var (
+	ErrFileNotExists          = errors.New("file does not exist")
+	ErrUnsupportedFile        = errors.New("unsupported file")
+	ErrOffsetExceedsFileSize  = errors.New("offset exceeds file size")
+	ErrEmptyDestinationPath   = errors.New("destination path is empty")
+	ErrEmptySourcePath        = errors.New("source path is empty")
+	ErrInvalidOffset          = errors.New("invalid offset")
+	ErrInvalidLimit           = errors.New("invalid limit")
+	ErrIsDirectory            = errors.New("source path is a directory")
+	ErrRangeInRecursiveMode   = errors.New("offset and limit are not supported in recursive mode")
+	ErrInvalidSymlinkPolicy   = errors.New("invalid symlink policy")
+	ErrSymlinkLoop            = errors.New("symlink loop")
+	ErrInvalidChecksum        = errors.New("invalid checksum algorithm")
+	ErrChecksumMismatch       = errors.New("checksum mismatch")
+	ErrInvalidSidecarMode     = errors.New("invalid sidecar mode")
+	ErrInvalidSidecar         = errors.New("invalid checksum file")
+	ErrUnknownCodec           = errors.New("unknown compression format")
+	ErrCodecConflict          = errors.New("compression is not supported with resume, sidecar files and recursive mode")
+	ErrSidecarInRecursiveMode = errors.New("checksum file is not supported in recursive mode")
+)
@@ -0,0 +1,114 @@
+package filecopy
+
+// Option changes default behaviour of Copy.
+type Option func(c *copier)
+
+// WithResume continues previously interrupted copying.
+func WithResume() Option {
+	return func(c *copier) {
+		c.resume = true
+	}
+}
+
+// WithOffset starts copying at offset of source.
+func WithOffset(offset int64) Option {
+	return func(c *copier) {
+		c.offset = offset
+	}
+}
+
+// WithLimit copies not more than limit bytes, whole source is copied if limit is 0.
+func WithLimit(limit int64) Option {
+	return func(c *copier) {
+		c.limit = limit
+	}
+}
+
+// WithProgress reports copying progress to p.
+func WithProgress(p Progress) Option {
+	return func(c *copier) {
+		c.progress = p
+	}
+}
+
+// WithRecursive allows to copy directories, files are copied by workers goroutines.
+// If workers is not positive, number of CPUs is used.
+func WithRecursive(workers int) Option {
+	return func(c *copier) {
+		c.recursive = true
+		c.workers = workers
+	}
+}
+
+// WithSymlinkPolicy defines how symlinks are handled in recursive mode.
+func WithSymlinkPolicy(policy SymlinkPolicy) Option {
+	return func(c *copier) {
+		c.symlinks = policy
+	}
+}
+
+// WithInclude copies only files matching any of glob patterns in recursive mode.
+// Pattern is matched against path relative to source directory and against file name.
+func WithInclude(patterns ...string) Option {
+	return func(c *copier) {
+		c.include = append(c.include, patterns...)
+	}
+}
+
+// WithExclude skips files and directories matching any of glob patterns in recursive mode.
+func WithExclude(patterns ...string) Option {
+	return func(c *copier) {
+		c.exclude = append(c.exclude, patterns...)
+	}
+}
+
+// WithParallel copies range of regular file by workers goroutines, every worker copies
+// chunkSize bytes at once (DefaultParallelChunkSize if it is not positive).
+// Streams, devices and sparse mode are copied sequentially.
+func WithParallel(workers int, chunkSize int64) Option {
+	return func(c *copier) {
+		c.parallel = workers
+		c.chunkSize = chunkSize
+	}
+}
+
+// WithSparse keeps holes of source in destination. Holes are found by file system
+// if it supports SEEK_DATA/SEEK_HOLE and by zero blocks of SparseBlockSize otherwise.
+func WithSparse() Option {
+	return func(c *copier) {
+		c.sparse = true
+	}
+}
+
+// WithCompress compresses selected range of source by registered codec, DefaultCodec is used if name is empty.
+func WithCompress(name string) Option {
+	return func(c *copier) {
+		c.compress = true
+		c.codec = name
+	}
+}
+
+// WithDecompress decompresses selected range of source by registered codec.
+// If name is empty, codec is detected by magic bytes of the range.
+func WithDecompress(name string) Option {
+	return func(c *copier) {
+		c.decompress = true
+		c.codec = name
+	}
+}
+
+// WithVerify calculates checksum of copied data by algorithm (sha256 or crc32c)
+// and compares it with checksum of data read back from destination.
+func WithVerify(algorithm string) Option {
+	return func(c *copier) {
+		c.verify = algorithm
+	}
+}
+
+// WithSidecar writes or checks sha256 checksum file placed near copied file.
+// It enables sha256 verification if no algorithm is set.
+func WithSidecar(mode SidecarMode) Option {
+	return func(c *copier) {
+		c.sidecar = mode
+	}
+}
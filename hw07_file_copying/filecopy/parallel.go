@@ -0,0 +1,185 @@
+package filecopy
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultParallelChunkSize is a size of range part copied by one worker if chunk size is not set.
+const DefaultParallelChunkSize = 16 << 20
+
+// chunkResult is a result of copying one chunk of range.
+type chunkResult struct {
+	index   int64
+	written int64
+	err     error
+}
+
+// parallelCopy copies n bytes from src at srcStart to dst at dstStart by chunks.
+type parallelCopy struct {
+	src       io.ReaderAt
+	dst       io.WriterAt
+	srcStart  int64
+	dstStart  int64
+	n         int64
+	chunkSize int64
+	workers   int
+}
+
+// parallelAllowed reports if the rest of range can be copied by parallel workers.
+// It requires known size, random access to source and destination and no sparse and codec modes.
+func (c *copier) parallelAllowed(src *os.File, dst *destination, total int64) bool {
+	if c.parallel < 1 || total == UnknownSize || c.sparse || c.transcoding() || total <= dst.written {
+		return false
+	}
+	if dst.fp != nil && !dst.regular() {
+		return false
+	}
+	if src != nil {
+		info, err := src.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return false
+		}
+	}
+	return true
+}
+
+// copyParallel copies the rest of range to preallocated destination.
+func (c *copier) copyParallel(src io.ReaderAt, dst *destination, total int64) <-chan progress {
+	p := parallelCopy{
+		src:       src,
+		srcStart:  c.offset + dst.written,
+		dstStart:  dst.written,
+		n:         total - dst.written,
+		chunkSize: c.chunkSize,
+		workers:   c.parallel,
+	}
+	if p.chunkSize <= 0 {
+		p.chunkSize = DefaultParallelChunkSize
+	}
+	if dst.fp != nil {
+		// preallocation is an optimization, write errors are reported anyway
+		_ = preallocate(dst.fp, total)
+		p.dst = dst.fp
+	} else {
+		p.dst = dst.out.w
+		dst.out.off = total
+	}
+	return c.copyParallelAsync(p)
+}
+
+// copyParallelAsync copies range by c.parallel workers using ReadAt/WriteAt.
+// Progress is reported for contiguous copied prefix only, so after failure destination
+// can be truncated to reported size and resumed. The first error cancels other workers.
+func (c *copier) copyParallelAsync(p parallelCopy) <-chan progress {
+	progressCh := make(chan progress)
+	go func() {
+		defer close(progressCh)
+		ctx, cancel := context.WithCancel(c.ctx)
+		defer cancel()
+
+		chunks := (p.n + p.chunkSize - 1) / p.chunkSize
+		workers := p.workers
+		if int64(workers) > chunks {
+			workers = int(chunks)
+		}
+		results := make(chan chunkResult, workers)
+		var nextChunk int64 = -1
+		wg := sync.WaitGroup{}
+		wg.Add(workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				defer wg.Done()
+				buff := make([]byte, BufferSize)
+				for {
+					index := atomic.AddInt64(&nextChunk, 1)
+					if index >= chunks || ctx.Err() != nil {
+						return
+					}
+					results <- p.copyChunk(ctx, index, buff)
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		var firstErr error
+		var next, pending int64
+		completed := map[int64]int64{}
+		lastReport := time.Now()
+		for res := range results {
+			if res.err != nil {
+				if firstErr == nil {
+					firstErr = res.err
+					cancel()
+				}
+				continue
+			}
+			completed[res.index] = res.written
+			for {
+				written, ok := completed[next]
+				if !ok {
+					break
+				}
+				delete(completed, next)
+				pending += written
+				next++
+			}
+			if time.Since(lastReport) >= ProgressInterval {
+				progressCh <- progress{add: pending}
+				pending = 0
+				lastReport = time.Now()
+			}
+		}
+		if firstErr == nil && next < chunks {
+			firstErr = ctx.Err() // cancelled by parent context
+		}
+		if pending > 0 || firstErr != nil {
+			progressCh <- progress{add: pending, error: firstErr}
+		}
+	}()
+	return progressCh
+}
+
+// copyChunk copies chunk with index, only the last chunk can be shorter than chunk size.
+func (p parallelCopy) copyChunk(ctx context.Context, index int64, buff []byte) chunkResult {
+	res := chunkResult{index: index}
+	start := index * p.chunkSize
+	size := p.chunkSize
+	if start+size > p.n {
+		size = p.n - start
+	}
+	for res.written < size {
+		if err := ctx.Err(); err != nil {
+			res.err = err
+			return res
+		}
+		part := buff
+		if left := size - res.written; left < int64(len(part)) {
+			part = part[:left]
+		}
+		n, readErr := p.src.ReadAt(part, p.srcStart+start+res.written)
+		if n > 0 {
+			if _, err := p.dst.WriteAt(part[:n], p.dstStart+start+res.written); err != nil {
+				res.err = err
+				return res
+			}
+			res.written += int64(n)
+		}
+		if readErr == io.EOF && res.written < size {
+			res.err = io.ErrUnexpectedEOF // source is truncated while copying
+			return res
+		}
+		if readErr != nil && readErr != io.EOF {
+			res.err = readErr
+			return res
+		}
+	}
+	return res
+}
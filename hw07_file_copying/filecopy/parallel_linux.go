@@ -0,0 +1,14 @@
+package filecopy
+
+import (
+	"os"
+	"syscall"
+)
+
+// preallocate reserves disk space for file of size bytes.
+func preallocate(fp *os.File, size int64) error {
+	if err := syscall.Fallocate(int(fp.Fd()), 0, 0, size); err != nil {
+		return fp.Truncate(size)
+	}
+	return nil
+}
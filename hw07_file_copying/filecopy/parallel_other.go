@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package filecopy
+
+import "os"
+
+// preallocate sets size of file, disk space is allocated by writes.
+func preallocate(fp *os.File, size int64) error {
+	return fp.Truncate(size)
+}
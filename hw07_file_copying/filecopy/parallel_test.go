@@ -0,0 +1,151 @@
+package filecopy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// failingReader returns error when data after failAt is read.
+type failingReader struct {
+	data   []byte
+	failAt int64
+	err    error
+}
+
+func (r failingReader) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > r.failAt {
+		return 0, r.err
+	}
+	return bytes.NewReader(r.data).ReadAt(p, off)
+}
+
+func TestParallel(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+
+	cases := []struct {
+		offset    int64
+		limit     int64
+		workers   int
+		chunkSize int64
+	}{
+		{0, 0, 4, 1000},
+		{0, 0, 3, 1},
+		{100, 1000, 4, 64},
+		{6000, 1000, 2, 1000},
+		{0, 10, 8, 1000},
+		{0, 0, 4, 0},
+	}
+	for _, tc := range cases {
+		tc := tc
+		name := fmt.Sprintf("offset %d limit %d workers %d chunk %d", tc.offset, tc.limit, tc.workers, tc.chunkSize)
+		t.Run(name, func(t *testing.T) {
+			end := int64(len(input))
+			if tc.limit > 0 && tc.offset+tc.limit < end {
+				end = tc.offset + tc.limit
+			}
+
+			to := filepath.Join(t.TempDir(), "out.txt")
+			p := &recordingProgress{}
+			err := Copy("../testdata/input.txt", to, tc.offset, tc.limit,
+				WithParallel(tc.workers, tc.chunkSize), WithProgress(p), WithVerify(ChecksumCRC32C))
+			require.NoError(t, err)
+			res, err := os.ReadFile(to)
+			require.NoError(t, err)
+			require.Equal(t, input[tc.offset:end], res)
+			require.Equal(t, end-tc.offset, p.copied())
+
+			dst := &memFile{}
+			err = CopyContext(context.Background(), FromReader(bytes.NewReader(input), int64(len(input))),
+				ToWriter(dst), WithOffset(tc.offset), WithLimit(tc.limit), WithParallel(tc.workers, tc.chunkSize),
+				WithVerify(ChecksumSHA256))
+			require.NoError(t, err)
+			require.Equal(t, input[tc.offset:end], dst.data)
+		})
+	}
+
+	t.Run("stream is copied sequentially", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out")
+		require.NoError(t, Copy("/dev/zero", to, 0, 1000, WithParallel(4, 100)))
+		res, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, make([]byte, 1000), res)
+	})
+}
+
+func TestParallelErrors(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+	errRead := errors.New("read error")
+	size := int64(len(input))
+
+	t.Run("error keeps copied prefix for resuming", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		src := failingReader{data: input, failAt: 3500, err: errRead}
+		p := &recordingProgress{}
+		err := CopyContext(context.Background(), FromReader(src, size), ToPath(to),
+			WithParallel(4, 1000), WithResume(), WithProgress(p))
+		require.ErrorIs(t, err, errRead)
+
+		part, err := os.ReadFile(to + partSuffix)
+		require.NoError(t, err)
+		require.Equal(t, input[:p.copied()], part)
+		require.LessOrEqual(t, p.copied(), int64(3000))
+		require.Zero(t, p.copied()%1000)
+		require.NoFileExists(t, to)
+
+		p = &recordingProgress{}
+		err = CopyContext(context.Background(), FromReader(bytes.NewReader(input), size), ToPath(to),
+			WithParallel(4, 1000), WithResume(), WithProgress(p))
+		require.NoError(t, err)
+		res, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, input, res)
+		if len(part) > 0 {
+			require.Equal(t, int64(len(part)), p.adds[0])
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		errWrite := errors.New("write error")
+		err := CopyContext(context.Background(), FromReader(bytes.NewReader(input), size),
+			ToWriter(failingWriterAt{errWrite}), WithParallel(4, 100))
+		require.ErrorIs(t, err, errWrite)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		to := filepath.Join(t.TempDir(), "out.txt")
+		err := CopyContext(ctx, FromPath("../testdata/input.txt"), ToPath(to), WithParallel(4, 100))
+		require.ErrorIs(t, err, context.Canceled)
+		require.NoFileExists(t, to)
+		require.NoFileExists(t, to+partSuffix)
+	})
+
+	t.Run("truncated source", func(t *testing.T) {
+		dst := &memFile{}
+		err := CopyContext(context.Background(), FromReader(bytes.NewReader(input[:3000]), size),
+			ToWriter(dst), WithParallel(4, 1000))
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+type failingWriterAt struct {
+	err error
+}
+
+func (w failingWriterAt) WriteAt([]byte, int64) (int, error) {
+	return 0, w.err
+}
@@ -0,0 +1,26 @@
+package filecopy
+
+// Task describes started copying.
+type Task struct {
+	From string
+	To   string
+	// Total is a number of bytes to copy, it is UnknownSize if source is read until EOF.
+	Total int64
+}
+
+// Progress receives copying progress.
+// In recursive mode Add is called concurrently by workers.
+type Progress interface {
+	// Start is called before copying.
+	Start(task Task)
+	// Add is called when n more bytes are copied, resumed and sparse data is reported as copied too.
+	Add(n int64)
+	// Finish is called when copying is finished, successfully or not.
+	Finish()
+}
+
+type nopProgress struct{}
+
+func (nopProgress) Start(Task) {}
+func (nopProgress) Add(int64)  {}
+func (nopProgress) Finish()    {}
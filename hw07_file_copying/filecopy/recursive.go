@@ -0,0 +1,273 @@
+package filecopy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// SymlinkPolicy defines how symlinks are handled in recursive mode.
+type SymlinkPolicy int
+
+const (
+	// SymlinkCopy creates symlink with the same target.
+	SymlinkCopy SymlinkPolicy = iota
+	// SymlinkFollow copies file or directory which symlink points to.
+	SymlinkFollow
+	// SymlinkSkip ignores symlinks.
+	SymlinkSkip
+)
+
+var symlinkPolicies = map[string]SymlinkPolicy{
+	"copy":   SymlinkCopy,
+	"follow": SymlinkFollow,
+	"skip":   SymlinkSkip,
+}
+
+// ParseSymlinkPolicy converts policy name (copy, follow or skip) to SymlinkPolicy.
+func ParseSymlinkPolicy(name string) (SymlinkPolicy, error) {
+	policy, ok := symlinkPolicies[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSymlinkPolicy, name)
+	}
+	return policy, nil
+}
+
+// treeEntry is a file, directory or symlink to be created in destination.
+type treeEntry struct {
+	from string
+	to   string
+	info os.FileInfo
+}
+
+// tree is a result of source directory walking.
+type tree struct {
+	dirs  []treeEntry // parents go before children
+	files []treeEntry
+	links []treeEntry
+	size  int64
+	errs  CopyErrors
+}
+
+// copyDir copies directory tree, files are copied concurrently.
+// Failures do not stop copying, they are returned as CopyErrors.
+func (c *copier) copyDir() error {
+	if c.offset > 0 || c.limit > 0 {
+		return ErrRangeInRecursiveMode
+	}
+	if c.toPath == StdioPath {
+		return NewCopyError(c.toPath, ErrUnsupportedFile)
+	}
+	for _, pattern := range append(c.include, c.exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%s: %w", pattern, err)
+		}
+	}
+	if info, err := os.Stat(c.toPath); err == nil && info.IsDir() {
+		c.toPath = filepath.Join(c.toPath, filepath.Base(c.fromPath))
+	}
+
+	t := &tree{}
+	rootInfo, err := os.Stat(c.fromPath)
+	if err != nil {
+		return NewCopyError(c.fromPath, err)
+	}
+	t.dirs = append(t.dirs, treeEntry{from: c.fromPath, to: c.toPath, info: rootInfo})
+	t.walk(c, c.fromPath, c.toPath, "", map[string]bool{})
+
+	c.progress.Start(Task{From: c.fromPath, To: c.toPath, Total: t.size})
+	defer c.progress.Finish()
+	for _, d := range t.dirs {
+		// permissions are set after copying, directory should be writable till then
+		if err := os.MkdirAll(d.to, d.info.Mode().Perm()|0o700); err != nil {
+			t.errs = append(t.errs, NewCopyError(d.to, err))
+		}
+	}
+	t.copyFiles(c)
+	for _, l := range t.links {
+		if err := copySymlink(l); err != nil {
+			t.errs = append(t.errs, err)
+		}
+	}
+	for i := len(t.dirs) - 1; i >= 0; i-- {
+		if err := copyAttributes(t.dirs[i]); err != nil {
+			t.errs = append(t.errs, err)
+		}
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	if len(t.errs) == 0 {
+		return nil
+	}
+	sort.Slice(t.errs, func(i, j int) bool {
+		return t.errs[i].Path < t.errs[j].Path
+	})
+	return t.errs
+}
+
+// walk collects entries of directory from, visited contains real paths of walked directories.
+func (t *tree) walk(c *copier, from, to, rel string, visited map[string]bool) {
+	realPath, err := filepath.EvalSymlinks(from)
+	if err != nil {
+		t.errs = append(t.errs, NewCopyError(from, err))
+		return
+	}
+	if visited[realPath] {
+		t.errs = append(t.errs, NewCopyError(from, ErrSymlinkLoop))
+		return
+	}
+	visited[realPath] = true
+	defer delete(visited, realPath)
+
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		t.errs = append(t.errs, NewCopyError(from, err))
+		return
+	}
+	for _, e := range entries {
+		entry := treeEntry{from: filepath.Join(from, e.Name()), to: filepath.Join(to, e.Name())}
+		entryRel := filepath.Join(rel, e.Name())
+		if c.matchAny(c.exclude, entryRel) {
+			continue
+		}
+		entry.info, err = os.Lstat(entry.from)
+		if err != nil {
+			t.errs = append(t.errs, NewCopyError(entry.from, err))
+			continue
+		}
+		if entry.info.Mode()&os.ModeSymlink != 0 {
+			switch c.symlinks {
+			case SymlinkSkip:
+				continue
+			case SymlinkCopy:
+				if c.included(entryRel) {
+					t.links = append(t.links, entry)
+				}
+				continue
+			case SymlinkFollow:
+				entry.info, err = os.Stat(entry.from)
+				if err != nil {
+					t.errs = append(t.errs, NewCopyError(entry.from, err))
+					continue
+				}
+			}
+		}
+		switch {
+		case entry.info.IsDir():
+			t.dirs = append(t.dirs, entry)
+			t.walk(c, entry.from, entry.to, entryRel, visited)
+		case entry.info.Mode().IsRegular():
+			if c.included(entryRel) {
+				t.files = append(t.files, entry)
+				t.size += entry.info.Size()
+			}
+		default:
+			t.errs = append(t.errs, NewCopyError(entry.from, ErrUnsupportedFile))
+		}
+	}
+}
+
+// copyFiles copies regular files by c.workers goroutines.
+func (t *tree) copyFiles(c *copier) {
+	workers := c.workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	jobs := make(chan treeEntry)
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if err := c.copyTreeFile(job); err != nil {
+					mu.Lock()
+					t.errs = append(t.errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, f := range t.files {
+		if c.ctx.Err() != nil {
+			break
+		}
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// copyTreeFile copies single file of directory tree and its attributes.
+func (c *copier) copyTreeFile(job treeEntry) *CopyError {
+	fc := &copier{
+		ctx:       c.ctx,
+		fromPath:  job.from,
+		toPath:    job.to,
+		resume:    c.resume,
+		progress:  c.progress,
+		parallel:  c.parallel,
+		chunkSize: c.chunkSize,
+		sparse:    c.sparse,
+		verify:    c.verify,
+	}
+	if err := fc.copyFile(job.info.Size()); err != nil {
+		var copyErr *CopyError
+		if errors.As(err, &copyErr) {
+			return copyErr
+		}
+		return NewCopyError(job.from, err)
+	}
+	return copyAttributes(job)
+}
+
+// copySymlink creates symlink pointing to the same target as source one.
+func copySymlink(link treeEntry) *CopyError {
+	target, err := os.Readlink(link.from)
+	if err != nil {
+		return NewCopyError(link.from, err)
+	}
+	if err := os.Remove(link.to); err != nil && !os.IsNotExist(err) {
+		return NewCopyError(link.to, err)
+	}
+	if err := os.Symlink(target, link.to); err != nil {
+		return NewCopyError(link.to, err)
+	}
+	return nil
+}
+
+// copyAttributes sets permissions and modification time of source to destination.
+func copyAttributes(entry treeEntry) *CopyError {
+	if err := os.Chmod(entry.to, entry.info.Mode().Perm()); err != nil {
+		return NewCopyError(entry.to, err)
+	}
+	if err := os.Chtimes(entry.to, entry.info.ModTime(), entry.info.ModTime()); err != nil {
+		return NewCopyError(entry.to, err)
+	}
+	return nil
+}
+
+// included checks include patterns, everything is included if there are no patterns.
+func (c *copier) included(rel string) bool {
+	return len(c.include) == 0 || c.matchAny(c.include, rel)
+}
+
+// matchAny checks if relative path or file name matches any of patterns.
+func (c *copier) matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+package filecopy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// makeTree creates directory tree described by map of relative paths to contents.
+// Paths ending with / are directories.
+func makeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if strings.HasSuffix(name, "/") {
+			require.NoError(t, os.MkdirAll(p, 0o755))
+			continue
+		}
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+}
+
+// listTree returns relative paths of all entries of directory tree.
+func listTree(t *testing.T, root string) []string {
+	t.Helper()
+	var paths []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != root {
+			rel, _ := filepath.Rel(root, p)
+			paths = append(paths, rel)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(paths)
+	return paths
+}
+
+func TestRecursive(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	files := map[string]string{
+		"a.txt":         "aaa",
+		"b.log":         "bbb",
+		"sub/c.txt":     "ccc",
+		"sub/deep/d.go": "ddd",
+		"empty/":        "",
+		"skip/e.txt":    "eee",
+	}
+
+	t.Run("tree", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "src")
+		dst := filepath.Join(t.TempDir(), "dst")
+		makeTree(t, src, files)
+
+		err := Copy(src, dst, 0, 0, WithRecursive(2))
+		require.NoError(t, err)
+		require.Equal(t, listTree(t, src), listTree(t, dst))
+		for name, content := range files {
+			if strings.HasSuffix(name, "/") {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dst, name))
+			require.NoError(t, err)
+			require.Equal(t, content, string(data))
+		}
+	})
+
+	t.Run("into existing directory", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "src")
+		dst := t.TempDir()
+		makeTree(t, src, files)
+
+		err := Copy(src, dst, 0, 0, WithRecursive(0))
+		require.NoError(t, err)
+		require.Equal(t, listTree(t, src), listTree(t, filepath.Join(dst, "src")))
+	})
+
+	t.Run("attributes", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "src")
+		dst := filepath.Join(t.TempDir(), "dst")
+		makeTree(t, src, files)
+		mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		require.NoError(t, os.Chmod(filepath.Join(src, "a.txt"), 0o600))
+		require.NoError(t, os.Chtimes(filepath.Join(src, "a.txt"), mtime, mtime))
+		require.NoError(t, os.Chmod(filepath.Join(src, "sub", "deep"), 0o500))
+		require.NoError(t, os.Chtimes(filepath.Join(src, "sub"), mtime, mtime))
+		defer func() {
+			_ = os.Chmod(filepath.Join(src, "sub", "deep"), 0o755)
+			_ = os.Chmod(filepath.Join(dst, "sub", "deep"), 0o755)
+		}()
+
+		err := Copy(src, dst, 0, 0, WithRecursive(2))
+		require.NoError(t, err)
+
+		info, err := os.Stat(filepath.Join(dst, "a.txt"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		require.True(t, mtime.Equal(info.ModTime()))
+
+		info, err = os.Stat(filepath.Join(dst, "sub", "deep"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o500), info.Mode().Perm())
+
+		info, err = os.Stat(filepath.Join(dst, "sub"))
+		require.NoError(t, err)
+		require.True(t, mtime.Equal(info.ModTime()))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			opts     []Option
+			expected []string
+		}{
+			{
+				name: "include",
+				opts: []Option{WithInclude("*.txt")},
+				expected: []string{
+					"a.txt", "empty", "skip", "skip/e.txt", "sub", "sub/c.txt", "sub/deep",
+				},
+			},
+			{
+				name: "exclude",
+				opts: []Option{WithExclude("skip", "*.log")},
+				expected: []string{
+					"a.txt", "empty", "sub", "sub/c.txt", "sub/deep", "sub/deep/d.go",
+				},
+			},
+			{
+				name:     "relative path",
+				opts:     []Option{WithInclude("sub/*"), WithExclude("sub/deep")},
+				expected: []string{"empty", "skip", "sub", "sub/c.txt"},
+			},
+		}
+		for _, tc := range cases {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				src := filepath.Join(t.TempDir(), "src")
+				dst := filepath.Join(t.TempDir(), "dst")
+				makeTree(t, src, files)
+
+				opts := append([]Option{WithRecursive(2)}, tc.opts...)
+				err := Copy(src, dst, 0, 0, opts...)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, listTree(t, dst))
+			})
+		}
+	})
+
+	t.Run("bad pattern", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "src")
+		makeTree(t, src, files)
+
+		err := Copy(src, filepath.Join(t.TempDir(), "dst"), 0, 0, WithRecursive(1), WithInclude("["))
+		require.ErrorIs(t, err, filepath.ErrBadPattern)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "src")
+		makeTree(t, src, files)
+
+		err := Copy(src, filepath.Join(t.TempDir(), "dst"), 10, 0, WithRecursive(1))
+		require.ErrorIs(t, err, ErrRangeInRecursiveMode)
+		err = Copy(src, filepath.Join(t.TempDir(), "dst"), 0, 10, WithRecursive(1))
+		require.ErrorIs(t, err, ErrRangeInRecursiveMode)
+	})
+
+	t.Run("not recursive", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "src")
+		makeTree(t, src, files)
+
+		err := Copy(src, filepath.Join(t.TempDir(), "dst"), 0, 0)
+		require.ErrorIs(t, err, ErrIsDirectory)
+	})
+}
+
+func TestRecursiveSymlinks(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	prepare := func(t *testing.T) string {
+		t.Helper()
+		src := filepath.Join(t.TempDir(), "src")
+		makeTree(t, src, map[string]string{
+			"file.txt":    "data",
+			"dir/in.txt":  "in",
+			"other/x.txt": "x",
+		})
+		require.NoError(t, os.Symlink("file.txt", filepath.Join(src, "link.txt")))
+		require.NoError(t, os.Symlink("../other", filepath.Join(src, "dir", "other")))
+		return src
+	}
+
+	t.Run("copy", func(t *testing.T) {
+		src := prepare(t)
+		dst := filepath.Join(t.TempDir(), "dst")
+
+		err := Copy(src, dst, 0, 0, WithRecursive(2))
+		require.NoError(t, err)
+
+		target, err := os.Readlink(filepath.Join(dst, "link.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "file.txt", target)
+		target, err = os.Readlink(filepath.Join(dst, "dir", "other"))
+		require.NoError(t, err)
+		require.Equal(t, "../other", target)
+		data, err := os.ReadFile(filepath.Join(dst, "dir", "other", "x.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "x", string(data))
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		src := prepare(t)
+		dst := filepath.Join(t.TempDir(), "dst")
+
+		err := Copy(src, dst, 0, 0, WithRecursive(2), WithSymlinkPolicy(SymlinkFollow))
+		require.NoError(t, err)
+
+		info, err := os.Lstat(filepath.Join(dst, "link.txt"))
+		require.NoError(t, err)
+		require.True(t, info.Mode().IsRegular())
+		info, err = os.Lstat(filepath.Join(dst, "dir", "other"))
+		require.NoError(t, err)
+		require.True(t, info.IsDir())
+		data, err := os.ReadFile(filepath.Join(dst, "dir", "other", "x.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "x", string(data))
+	})
+
+	t.Run("follow loop", func(t *testing.T) {
+		src := prepare(t)
+		dst := filepath.Join(t.TempDir(), "dst")
+		require.NoError(t, os.Symlink("..", filepath.Join(src, "dir", "loop")))
+
+		err := Copy(src, dst, 0, 0, WithRecursive(2), WithSymlinkPolicy(SymlinkFollow))
+		var copyErrs CopyErrors
+		require.True(t, errors.As(err, &copyErrs))
+		require.Len(t, copyErrs, 1)
+		require.Equal(t, filepath.Join(src, "dir", "loop"), copyErrs[0].Path)
+		require.ErrorIs(t, copyErrs[0], ErrSymlinkLoop)
+
+		data, err := os.ReadFile(filepath.Join(dst, "link.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "data", string(data))
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		src := prepare(t)
+		dst := filepath.Join(t.TempDir(), "dst")
+
+		err := Copy(src, dst, 0, 0, WithRecursive(2), WithSymlinkPolicy(SymlinkSkip))
+		require.NoError(t, err)
+		require.Equal(t, []string{"dir", "dir/in.txt", "file.txt", "other", "other/x.txt"}, listTree(t, dst))
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		policy, err := ParseSymlinkPolicy("follow")
+		require.NoError(t, err)
+		require.Equal(t, SymlinkFollow, policy)
+		_, err = ParseSymlinkPolicy("unknown")
+		require.ErrorIs(t, err, ErrInvalidSymlinkPolicy)
+	})
+}
@@ -0,0 +1,63 @@
+//go:build !windows
+// +build !windows
+
+package filecopy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRecursiveFailures(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	src := filepath.Join(t.TempDir(), "src")
+	dst := filepath.Join(t.TempDir(), "dst")
+	makeTree(t, src, map[string]string{
+		"a.txt":        "a",
+		"locked.txt":   "locked",
+		"sub/b.txt":    "b",
+		"closed/c.txt": "c",
+	})
+	require.NoError(t, syscall.Mkfifo(filepath.Join(src, "sub", "fifo"), 0o644))
+	if os.Geteuid() != 0 {
+		// permissions are not checked for root
+		require.NoError(t, os.Chmod(filepath.Join(src, "locked.txt"), 0))
+		require.NoError(t, os.Chmod(filepath.Join(src, "closed"), 0))
+		defer func() {
+			_ = os.Chmod(filepath.Join(src, "closed"), 0o755)
+			_ = os.Chmod(filepath.Join(dst, "closed"), 0o755)
+		}()
+	}
+
+	err := Copy(src, dst, 0, 0, WithRecursive(2))
+	var copyErrs CopyErrors
+	require.True(t, errors.As(err, &copyErrs))
+
+	expected := []string{filepath.Join(src, "sub", "fifo")}
+	if os.Geteuid() != 0 {
+		expected = []string{
+			filepath.Join(src, "closed"),
+			filepath.Join(src, "locked.txt"),
+			filepath.Join(src, "sub", "fifo"),
+		}
+	}
+	paths := make([]string, 0, len(copyErrs))
+	for _, e := range copyErrs {
+		paths = append(paths, e.Path)
+	}
+	require.Equal(t, expected, paths)
+	require.ErrorIs(t, copyErrs[len(copyErrs)-1], ErrUnsupportedFile)
+
+	// other files are copied anyway
+	for _, name := range []string{"a.txt", "sub/b.txt"} {
+		_, err := os.Stat(filepath.Join(dst, name))
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,103 @@
+package filecopy
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+)
+
+const (
+	// StdioPath is used instead of path to read from stdin or to write to stdout.
+	StdioPath = "-"
+
+	// UnknownSize is a size of source which length can not be determined (pipes and devices).
+	UnknownSize = -1
+)
+
+// Source is a file or reader to copy from.
+type Source struct {
+	path   string
+	reader io.ReaderAt
+	size   int64
+}
+
+// FromPath creates source reading file, StdioPath means stdin.
+func FromPath(path string) Source {
+	return Source{path: path}
+}
+
+// FromReader creates source reading r of size bytes, size is UnknownSize if r is read until EOF.
+func FromReader(r io.ReaderAt, size int64) Source {
+	return Source{reader: r, size: size}
+}
+
+// statSource returns information about source file.
+func (c *copier) statSource() (os.FileInfo, error) {
+	if c.fromPath == StdioPath {
+		return os.Stdin.Stat()
+	}
+	return os.Stat(c.fromPath)
+}
+
+// openSource opens source file, stdin is returned for StdioPath.
+// File is nil if source is a reader.
+func (c *copier) openSource() (*os.File, io.ReaderAt, error) {
+	if c.reader != nil {
+		return nil, c.reader, nil
+	}
+	if c.fromPath == StdioPath {
+		return os.Stdin, os.Stdin, nil
+	}
+	fp, err := os.Open(c.fromPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fp, fp, nil
+}
+
+// seekSource returns reader of source starting at pos.
+// Offset beyond the end of source of unknown size is reported as ErrOffsetExceedsFileSize.
+func (c *copier) seekSource(fp *os.File, ra io.ReaderAt, pos int64) (io.Reader, error) {
+	if fp != nil {
+		return fp, skip(fp, pos)
+	}
+	if c.size != UnknownSize {
+		return io.NewSectionReader(ra, pos, c.size-pos), nil
+	}
+	if pos > 0 {
+		// the last skipped byte must exist
+		if _, err := ra.ReadAt(make([]byte, 1), pos-1); errors.Is(err, io.EOF) {
+			return nil, ErrOffsetExceedsFileSize
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return io.NewSectionReader(ra, pos, int64(math.MaxInt64)-pos), nil
+}
+
+// sourceSize returns size of regular file or UnknownSize for pipes and devices.
+func sourceSize(info os.FileInfo) int64 {
+	if info.Mode().IsRegular() {
+		return info.Size()
+	}
+	return UnknownSize
+}
+
+// skip moves source to pos, data of non-seekable sources is read and discarded.
+// Size of regular files is checked before, so they are just seeked.
+func skip(src *os.File, pos int64) error {
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Mode().IsRegular() {
+		_, err := src.Seek(pos, io.SeekStart)
+		return err
+	}
+	n, err := io.CopyN(io.Discard, src, pos)
+	if n < pos {
+		return ErrOffsetExceedsFileSize
+	}
+	return err
+}
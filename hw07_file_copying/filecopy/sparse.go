@@ -0,0 +1,150 @@
+package filecopy
+
+import (
+	"bytes"
+	"hash"
+	"io"
+	"os"
+)
+
+// SparseBlockSize is a size of block which is not written to destination if it contains only zeros.
+const SparseBlockSize = 4 << 10
+
+var zeroBlock = make([]byte, SparseBlockSize)
+
+// sparseWriter skips zero blocks instead of writing them, so they become holes in destination.
+// File size should be fixed by finish after the last write.
+type sparseWriter struct {
+	fp *os.File
+}
+
+func (w *sparseWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n := dataRun(p[written:])
+		if n > 0 {
+			if _, err := w.fp.Write(p[written : written+n]); err != nil {
+				return written, err
+			}
+			written += n
+		}
+		n = zeroRun(p[written:])
+		if n > 0 {
+			if err := w.skip(int64(n)); err != nil {
+				return written, err
+			}
+			written += n
+		}
+	}
+	return len(p), nil
+}
+
+// skip moves file offset forward without writing.
+func (w *sparseWriter) skip(n int64) error {
+	_, err := w.fp.Seek(n, io.SeekCurrent)
+	return err
+}
+
+// finish sets file size to current offset, so skipped tail becomes a hole.
+func (w *sparseWriter) finish() error {
+	pos, err := w.fp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	return w.fp.Truncate(pos)
+}
+
+// dataRun returns length of blocks from the beginning of p containing non-zero bytes.
+func dataRun(p []byte) int {
+	n := 0
+	for n < len(p) && !isZeroBlock(p[n:]) {
+		n += blockLen(p[n:])
+	}
+	return n
+}
+
+// zeroRun returns length of zero blocks from the beginning of p.
+func zeroRun(p []byte) int {
+	n := 0
+	for n < len(p) && isZeroBlock(p[n:]) {
+		n += blockLen(p[n:])
+	}
+	return n
+}
+
+func blockLen(p []byte) int {
+	if len(p) < SparseBlockSize {
+		return len(p)
+	}
+	return SparseBlockSize
+}
+
+func isZeroBlock(p []byte) bool {
+	n := blockLen(p)
+	return bytes.Equal(p[:n], zeroBlock[:n])
+}
+
+// copySparseAsync copies range [start, end) of src skipping holes reported by file system.
+// Data is read from r, which is src or reader wrapping it, hashing writer gets zeros for holes.
+func (c *copier) copySparseAsync(
+	src *os.File, r io.Reader, w *sparseWriter, start, end int64, h hash.Hash,
+) <-chan progress {
+	progressCh := make(chan progress)
+	go func() {
+		defer close(progressCh)
+		pos := start
+		for pos < end {
+			dataStart, dataEnd, err := nextData(src, pos, end)
+			if err != nil {
+				progressCh <- progress{error: err}
+				return
+			}
+			if err := copyHole(w, h, pos, dataStart, progressCh); err != nil {
+				progressCh <- progress{error: err}
+				return
+			}
+			if dataStart == dataEnd {
+				break
+			}
+			if _, err := src.Seek(dataStart, io.SeekStart); err != nil {
+				progressCh <- progress{error: err}
+				return
+			}
+			var copied int64
+			for prg := range c.copyAsync(r, w, dataEnd-dataStart) {
+				copied += prg.add
+				progressCh <- prg
+				if prg.error != nil {
+					return
+				}
+			}
+			if copied < dataEnd-dataStart {
+				return // file is truncated while copying
+			}
+			pos = dataEnd
+		}
+	}()
+	return progressCh
+}
+
+// copyHole skips hole [from, to) in destination and reports it as copied.
+func copyHole(w *sparseWriter, h io.Writer, from, to int64, progressCh chan<- progress) error {
+	if to <= from {
+		return nil
+	}
+	if err := w.skip(to - from); err != nil {
+		return err
+	}
+	if h != nil {
+		for n := to - from; n > 0; {
+			block := int64(SparseBlockSize)
+			if n < block {
+				block = n
+			}
+			_, _ = h.Write(zeroBlock[:block])
+			n -= block
+		}
+	}
+	progressCh <- progress{add: to - from}
+	return nil
+}
@@ -0,0 +1,39 @@
+package filecopy
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// whence values of lseek, they are not exported by syscall package.
+const (
+	seekData = 3
+	seekHole = 4
+)
+
+// nextData returns bounds of the first data region of src in [pos, end).
+// Empty region at end means that the rest of range is a hole.
+// If file system does not report holes, the whole range is data.
+func nextData(src *os.File, pos, end int64) (int64, int64, error) {
+	dataStart, err := src.Seek(pos, seekData)
+	switch {
+	case errors.Is(err, syscall.ENXIO):
+		return end, end, nil
+	case errors.Is(err, syscall.EINVAL):
+		return pos, end, nil
+	case err != nil:
+		return 0, 0, err
+	}
+	if dataStart >= end {
+		return end, end, nil
+	}
+	dataEnd, err := src.Seek(dataStart, seekHole)
+	if err != nil {
+		return 0, 0, err
+	}
+	if dataEnd > end {
+		dataEnd = end
+	}
+	return dataStart, dataEnd, nil
+}
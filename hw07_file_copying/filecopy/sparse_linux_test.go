@@ -0,0 +1,128 @@
+package filecopy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// allocated returns size of disk space used by file.
+func allocated(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info.Sys().(*syscall.Stat_t).Blocks * 512
+}
+
+// makeSparse creates file of size with data regions at given offsets, the rest is holes.
+func makeSparse(t *testing.T, path string, size int64, regions map[int64][]byte) {
+	t.Helper()
+	fp, err := os.Create(path)
+	require.NoError(t, err)
+	defer fp.Close()
+	require.NoError(t, fp.Truncate(size))
+	for off, data := range regions {
+		_, err := fp.WriteAt(data, off)
+		require.NoError(t, err)
+	}
+}
+
+func TestSparse(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	const size = 8 << 20
+	regions := map[int64][]byte{
+		0:       bytes.Repeat([]byte("a"), 64<<10),
+		4 << 20: bytes.Repeat([]byte("b"), 64<<10),
+	}
+	expected := make([]byte, size)
+	for off, data := range regions {
+		copy(expected[off:], data)
+	}
+
+	t.Run("holes", func(t *testing.T) {
+		dir := t.TempDir()
+		from := filepath.Join(dir, "in.img")
+		to := filepath.Join(dir, "out.img")
+		makeSparse(t, from, size, regions)
+
+		err := Copy(from, to, 0, 0, WithSparse())
+		require.NoError(t, err)
+		data, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+		require.LessOrEqual(t, allocated(t, to), allocated(t, from))
+	})
+
+	t.Run("zero blocks", func(t *testing.T) {
+		dir := t.TempDir()
+		from := filepath.Join(dir, "in.img")
+		to := filepath.Join(dir, "out.img")
+		require.NoError(t, os.WriteFile(from, expected, 0o644))
+
+		err := Copy(from, to, 0, 0, WithSparse())
+		require.NoError(t, err)
+		data, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+		require.Less(t, allocated(t, to), int64(size/4))
+		require.Equal(t, allocated(t, from), int64(size))
+	})
+
+	t.Run("offset and limit", func(t *testing.T) {
+		dir := t.TempDir()
+		from := filepath.Join(dir, "in.img")
+		to := filepath.Join(dir, "out.img")
+		makeSparse(t, from, size, regions)
+
+		err := Copy(from, to, 32<<10, 5<<20, WithSparse(), WithVerify(ChecksumSHA256))
+		require.NoError(t, err)
+		data, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, expected[32<<10:32<<10+5<<20], data)
+		require.Less(t, allocated(t, to), int64(1<<20))
+	})
+
+	t.Run("trailing hole", func(t *testing.T) {
+		dir := t.TempDir()
+		from := filepath.Join(dir, "in.img")
+		to := filepath.Join(dir, "out.img")
+		makeSparse(t, from, size, map[int64][]byte{0: []byte("head")})
+
+		err := Copy(from, to, 0, 0, WithSparse())
+		require.NoError(t, err)
+		info, err := os.Stat(to)
+		require.NoError(t, err)
+		require.Equal(t, int64(size), info.Size())
+		require.Less(t, allocated(t, to), int64(1<<20))
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		dir := t.TempDir()
+		to := filepath.Join(dir, "out.img")
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		go func() {
+			_, _ = w.Write(expected)
+			w.Close()
+		}()
+		stdin := os.Stdin
+		os.Stdin = r
+		defer func() {
+			os.Stdin = stdin
+			r.Close()
+		}()
+
+		err = Copy(StdioPath, to, 0, 0, WithSparse())
+		require.NoError(t, err)
+		data, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, expected, data)
+		require.Less(t, allocated(t, to), int64(size/4))
+	})
+}
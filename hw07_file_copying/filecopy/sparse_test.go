@@ -0,0 +1,42 @@
+package filecopy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseWriter(t *testing.T) {
+	block := func(b byte, n int) []byte {
+		return bytes.Repeat([]byte{b}, n)
+	}
+	data := bytes.Join([][]byte{
+		block(1, SparseBlockSize),
+		block(0, 2*SparseBlockSize),
+		block(2, SparseBlockSize/2),
+		block(0, SparseBlockSize/2),
+		block(0, 3*SparseBlockSize),
+	}, nil)
+
+	path := filepath.Join(t.TempDir(), "out")
+	fp, err := os.Create(path)
+	require.NoError(t, err)
+	defer fp.Close()
+
+	w := &sparseWriter{fp: fp}
+	n, err := w.Write(data)
+	require.NoError(t, err)
+	require.Equal(t, len(data), n)
+	require.NoError(t, w.finish())
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, data, written)
+
+	require.Equal(t, SparseBlockSize, dataRun(data))
+	require.Equal(t, 2*SparseBlockSize, zeroRun(data[SparseBlockSize:]))
+	require.Equal(t, 3*SparseBlockSize, zeroRun(data[4*SparseBlockSize:]))
+}
@@ -0,0 +1,180 @@
+package filecopy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Checksum algorithms supported by WithVerify.
+const (
+	ChecksumSHA256 = "sha256"
+	ChecksumCRC32C = "crc32c"
+)
+
+// sidecarSuffix is appended to file path to get name of its checksum file.
+const sidecarSuffix = ".sha256"
+
+// SidecarMode defines how checksum file placed near copied file is used.
+type SidecarMode int
+
+const (
+	// SidecarNone does not use checksum file.
+	SidecarNone SidecarMode = iota
+	// SidecarWrite writes checksum of copied data to destination path with .sha256 suffix.
+	SidecarWrite
+	// SidecarCheck compares checksum of copied data with source path with .sha256 suffix.
+	SidecarCheck
+)
+
+var sidecarModes = map[string]SidecarMode{
+	"":      SidecarNone,
+	"write": SidecarWrite,
+	"check": SidecarCheck,
+}
+
+// ParseSidecarMode converts mode name (write or check) to SidecarMode, empty name means SidecarNone.
+func ParseSidecarMode(name string) (SidecarMode, error) {
+	mode, ok := sidecarModes[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSidecarMode, name)
+	}
+	return mode, nil
+}
+
+// ChecksumError is returned when copied data does not match expected checksum.
+type ChecksumError struct {
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: %s expected %s, got %s", ErrChecksumMismatch, e.Algorithm, e.Expected, e.Actual)
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+var _ error = (*ChecksumError)(nil)
+
+// newHash creates hash for checksum algorithm.
+func newHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidChecksum, algorithm)
+}
+
+// checkVerifyOptions validates verification settings before copying.
+func (c *copier) checkVerifyOptions() error {
+	if c.sidecar != SidecarNone {
+		if c.verify == "" {
+			c.verify = ChecksumSHA256
+		}
+		if c.verify != ChecksumSHA256 {
+			return fmt.Errorf("%w: sidecar file requires %s", ErrInvalidChecksum, ChecksumSHA256)
+		}
+		if c.recursive {
+			return ErrSidecarInRecursiveMode
+		}
+	}
+	if c.verify == "" {
+		return nil
+	}
+	if _, err := newHash(c.verify); err != nil {
+		return err
+	}
+	if c.writer != nil {
+		if _, ok := c.writer.(io.ReaderAt); !ok || c.sidecar == SidecarWrite {
+			return ErrUnsupportedFile
+		}
+	} else if c.toPath == StdioPath {
+		// stdout can not be read back
+		return NewCopyError(c.toPath, ErrUnsupportedFile)
+	} else if info, err := os.Stat(c.toPath); err == nil && !info.IsDir() && !info.Mode().IsRegular() {
+		return NewCopyError(c.toPath, ErrUnsupportedFile)
+	}
+	if c.sidecar == SidecarCheck && (c.reader != nil || c.fromPath == StdioPath || c.offset > 0 || c.limit > 0) {
+		// sidecar file describes the whole source file
+		return NewCopyError(c.fromPath, ErrUnsupportedFile)
+	}
+	return nil
+}
+
+// readSidecar reads expected checksum from source sidecar file in sha256sum format.
+func (c *copier) readSidecar() error {
+	sidecarPath := c.fromPath + sidecarSuffix
+	data, err := os.ReadFile(sidecarPath)
+	if err != nil {
+		return NewCopyError(sidecarPath, err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return NewCopyError(sidecarPath, ErrInvalidSidecar)
+	}
+	if _, err := hex.DecodeString(fields[0]); err != nil || len(fields[0]) != sha256.Size*2 {
+		return NewCopyError(sidecarPath, ErrInvalidSidecar)
+	}
+	c.expectedSum = strings.ToLower(fields[0])
+	return nil
+}
+
+// writeSidecar writes checksum of destination file in sha256sum format.
+func (c *copier) writeSidecar(sum []byte) error {
+	sidecarPath := c.toPath + sidecarSuffix
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum), filepath.Base(c.toPath))
+	if err := os.WriteFile(sidecarPath, []byte(line), 0o666); err != nil {
+		return NewCopyError(sidecarPath, err)
+	}
+	return nil
+}
+
+// hashWritten adds already copied prefix of source to hash, it is used when copying is resumed.
+func (c *copier) hashWritten(h hash.Hash, src io.ReaderAt, written int64) error {
+	if written == 0 {
+		return nil
+	}
+	_, err := io.Copy(h, io.NewSectionReader(src, c.offset, written))
+	return err
+}
+
+// verifyDestination reads written data back and compares its checksum with checksum of source.
+func (c *copier) verifyDestination(d *destination, sum []byte) error {
+	r, err := d.readBack()
+	if err != nil {
+		return NewCopyError(d.path, err)
+	}
+	h, _ := newHash(c.verify)
+	if _, err := io.Copy(h, r); err != nil {
+		return NewCopyError(d.path, err)
+	}
+	if actual := h.Sum(nil); !bytes.Equal(sum, actual) {
+		return NewCopyError(d.path, &ChecksumError{
+			Algorithm: c.verify,
+			Expected:  hex.EncodeToString(sum),
+			Actual:    hex.EncodeToString(actual),
+		})
+	}
+	if c.sidecar == SidecarCheck {
+		if actual := hex.EncodeToString(sum); actual != c.expectedSum {
+			return NewCopyError(c.fromPath, &ChecksumError{
+				Algorithm: c.verify,
+				Expected:  c.expectedSum,
+				Actual:    actual,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package filecopy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestVerify(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+
+	for _, algorithm := range []string{ChecksumSHA256, ChecksumCRC32C} {
+		algorithm := algorithm
+		t.Run(algorithm, func(t *testing.T) {
+			to := filepath.Join(t.TempDir(), "out.txt")
+			err := Copy("../testdata/input.txt", to, 100, 1000, WithVerify(algorithm))
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(to)
+			require.NoError(t, err)
+			require.Equal(t, input[100:1100], data)
+		})
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(to+partSuffix, input[:3000], 0o644))
+
+		err := Copy("../testdata/input.txt", to, 0, 0, WithResume(), WithVerify(ChecksumSHA256))
+		require.NoError(t, err)
+		data, err := os.ReadFile(to)
+		require.NoError(t, err)
+		require.Equal(t, input, data)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		to := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(to, []byte("corrupted"), 0o644))
+		fp, err := os.Open(to)
+		require.NoError(t, err)
+		defer fp.Close()
+
+		c := &copier{fromPath: "../testdata/input.txt", toPath: to, verify: ChecksumSHA256}
+		sum := sha256.Sum256([]byte("original"))
+		err = c.verifyDestination(&destination{fp: fp, path: to}, sum[:])
+
+		var copyErr *CopyError
+		require.True(t, errors.As(err, &copyErr))
+		require.Equal(t, to, copyErr.Path)
+		var checksumErr *ChecksumError
+		require.True(t, errors.As(err, &checksumErr))
+		require.Equal(t, hex.EncodeToString(sum[:]), checksumErr.Expected)
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "src")
+		makeTree(t, src, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+
+		err := Copy(src, filepath.Join(t.TempDir(), "dst"), 0, 0,
+			WithRecursive(2), WithVerify(ChecksumCRC32C))
+		require.NoError(t, err)
+	})
+}
+
+func TestSidecar(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	input, err := os.ReadFile("../testdata/input.txt")
+	require.NoError(t, err)
+	sum := sha256.Sum256(input)
+	expected := hex.EncodeToString(sum[:]) + "  out.txt\n"
+
+	t.Run("write and check", func(t *testing.T) {
+		dir := t.TempDir()
+		to := filepath.Join(dir, "out.txt")
+		err := Copy("../testdata/input.txt", to, 0, 0, WithSidecar(SidecarWrite))
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(to + sidecarSuffix)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(data))
+
+		err = Copy(to, filepath.Join(dir, "copy.txt"), 0, 0, WithSidecar(SidecarCheck))
+		require.NoError(t, err)
+	})
+
+	t.Run("check mismatch", func(t *testing.T) {
+		dir := t.TempDir()
+		from := filepath.Join(dir, "in.txt")
+		to := filepath.Join(dir, "out.txt")
+		require.NoError(t, os.WriteFile(from, input, 0o644))
+		other := sha256.Sum256([]byte("other"))
+		require.NoError(t, os.WriteFile(from+sidecarSuffix, []byte(hex.EncodeToString(other[:])+"  in.txt\n"), 0o644))
+
+		err := Copy(from, to, 0, 0, WithSidecar(SidecarCheck))
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+		var copyErr *CopyError
+		require.True(t, errors.As(err, &copyErr))
+		require.Equal(t, from, copyErr.Path)
+
+		// mismatched data is not left in destination
+		require.NoFileExists(t, to)
+		require.NoFileExists(t, to+partSuffix)
+	})
+
+	t.Run("invalid sidecar", func(t *testing.T) {
+		dir := t.TempDir()
+		from := filepath.Join(dir, "in.txt")
+		require.NoError(t, os.WriteFile(from, input, 0o644))
+
+		err := Copy(from, filepath.Join(dir, "out.txt"), 0, 0, WithSidecar(SidecarCheck))
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		require.NoError(t, os.WriteFile(from+sidecarSuffix, []byte("not a checksum\n"), 0o644))
+		err = Copy(from, filepath.Join(dir, "out.txt"), 0, 0, WithSidecar(SidecarCheck))
+		require.ErrorIs(t, err, ErrInvalidSidecar)
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		mode, err := ParseSidecarMode("check")
+		require.NoError(t, err)
+		require.Equal(t, SidecarCheck, mode)
+		_, err = ParseSidecarMode("unknown")
+		require.ErrorIs(t, err, ErrInvalidSidecarMode)
+	})
+}
+
+func TestVerifyErrors(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	makeTree(t, src, map[string]string{"a.txt": "a"})
+
+	cases := []struct {
+		name     string
+		from     string
+		to       string
+		offset   int64
+		opts     []Option
+		expected error
+	}{
+		{"unknown algorithm", "../testdata/input.txt", "out.txt", 0, []Option{WithVerify("md5")}, ErrInvalidChecksum},
+		{"stdout", "../testdata/input.txt", StdioPath, 0, []Option{WithVerify(ChecksumSHA256)}, ErrUnsupportedFile},
+		{"device", "../testdata/input.txt", "/dev/null", 0, []Option{WithVerify(ChecksumSHA256)}, ErrUnsupportedFile},
+		{
+			"sidecar algorithm", "../testdata/input.txt", "out.txt", 0,
+			[]Option{WithVerify(ChecksumCRC32C), WithSidecar(SidecarWrite)}, ErrInvalidChecksum,
+		},
+		{
+			"sidecar range", "../testdata/input.txt", "out.txt", 10,
+			[]Option{WithSidecar(SidecarCheck)}, ErrUnsupportedFile,
+		},
+		{
+			"sidecar recursive", src, "out", 0,
+			[]Option{WithRecursive(1), WithSidecar(SidecarWrite)}, ErrSidecarInRecursiveMode,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			to := tc.to
+			if to != StdioPath && !filepath.IsAbs(to) {
+				to = filepath.Join(dir, to)
+			}
+			opts := append([]Option{}, tc.opts...)
+			err := Copy(tc.from, to, tc.offset, 0, opts...)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
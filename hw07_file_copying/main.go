@@ -1,26 +1,154 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+
+	"github.com/fixme_my_friend/hw07_file_copying/filecopy"
 )
 
 var (
-	from, to      string
-	limit, offset int64
+	from, to         string
+	limit, offset    int64
+	resume, sparse   bool
+	recursive        bool
+	workers          int
+	parallel         int
+	chunkSize        int64
+	symlinks         string
+	include, exclude string
+	verify, sidecar  string
+	compress         string
+	decompress       string
 )
 
 func init() {
-	flag.StringVar(&from, "from", "", "file to read from")
-	flag.StringVar(&to, "to", "", "file to write to")
+	flag.StringVar(&from, "from", "", "file to read from, - for stdin")
+	flag.StringVar(&to, "to", "", "file to write to, - for stdout")
 	flag.Int64Var(&limit, "limit", 0, "limit of bytes to copy")
 	flag.Int64Var(&offset, "offset", 0, "offset in input file")
+	flag.BoolVar(&resume, "resume", false, "continue interrupted copying")
+	flag.IntVar(&parallel, "parallel", 0, "number of goroutines copying chunks of large file, 0 for sequential copying")
+	flag.Int64Var(&chunkSize, "chunk-size", filecopy.DefaultParallelChunkSize, "size of chunk in parallel mode")
+	flag.BoolVar(&sparse, "sparse", false, "keep holes of source file in destination")
+	flag.BoolVar(&recursive, "recursive", false, "copy directory recursively")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "number of files copied concurrently in recursive mode")
+	flag.StringVar(&symlinks, "symlinks", "copy", "symlinks handling in recursive mode: copy, follow or skip")
+	flag.StringVar(&include, "include", "", "comma-separated glob patterns of files to copy in recursive mode")
+	flag.StringVar(&exclude, "exclude", "",
+		"comma-separated glob patterns of files and directories to skip in recursive mode")
+	flag.StringVar(&verify, "verify", "", "verify copied data by checksum: sha256 or crc32c")
+	flag.StringVar(&sidecar, "sidecar", "", "write or check sha256 checksum file with .sha256 suffix: write or check")
+	flag.StringVar(&compress, "compress", "", "compress copied data by codec, e.g. gzip")
+	flag.StringVar(&decompress, "decompress", "", "decompress copied data by codec, auto to detect format")
 }
 
 func main() {
 	flag.Parse()
-	err := Copy(from, to, offset, limit)
+	var opts []filecopy.Option
+	if resume {
+		opts = append(opts, filecopy.WithResume())
+	}
+	if parallel > 0 {
+		opts = append(opts, filecopy.WithParallel(parallel, chunkSize))
+	}
+	if sparse {
+		opts = append(opts, filecopy.WithSparse())
+	}
+	if verify != "" {
+		opts = append(opts, filecopy.WithVerify(verify))
+	}
+	if compress != "" {
+		opts = append(opts, filecopy.WithCompress(compress))
+	}
+	switch decompress {
+	case "":
+	case "auto":
+		opts = append(opts, filecopy.WithDecompress(""))
+	default:
+		opts = append(opts, filecopy.WithDecompress(decompress))
+	}
+	if sidecar != "" {
+		mode, err := filecopy.ParseSidecarMode(sidecar)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		opts = append(opts, filecopy.WithSidecar(mode))
+	}
+	if recursive {
+		policy, err := filecopy.ParseSymlinkPolicy(symlinks)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		opts = append(opts,
+			filecopy.WithRecursive(workers),
+			filecopy.WithSymlinkPolicy(policy),
+			filecopy.WithInclude(splitPatterns(include)...),
+			filecopy.WithExclude(splitPatterns(exclude)...),
+		)
+	}
+	opts = append(opts,
+		filecopy.WithOffset(offset),
+		filecopy.WithLimit(limit),
+		filecopy.WithProgress(newBarProgress(to == filecopy.StdioPath)),
+	)
+
+	// interrupted copying leaves no temporary file, or keeps it in resume mode
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err := filecopy.CopyContext(ctx, filecopy.FromPath(from), filecopy.ToPath(to), opts...)
 	if err != nil {
 		fmt.Println(err)
 	}
 }
+
+// getMessage describes copying and its flags.
+func getMessage(task filecopy.Task) string {
+	msg := fmt.Sprintf("Copying %s -> %s", task.From, task.To)
+	if limit > 0 {
+		msg += fmt.Sprintf(", limit = %d", limit)
+	}
+	if offset > 0 {
+		msg += fmt.Sprintf(", offset = %d", offset)
+	}
+	if resume {
+		msg += ", resume"
+	}
+	if recursive {
+		msg += ", recursive"
+	}
+	if verify != "" {
+		msg += ", verify = " + verify
+	}
+	if parallel > 0 {
+		msg += fmt.Sprintf(", parallel = %d", parallel)
+	}
+	if sparse {
+		msg += ", sparse"
+	}
+	if compress != "" {
+		msg += ", compress = " + compress
+	}
+	if decompress != "" {
+		msg += ", decompress = " + decompress
+	}
+	return msg
+}
+
+// splitPatterns parses comma-separated list of patterns.
+func splitPatterns(list string) []string {
+	var patterns []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cheggaaa/pb"
+	"github.com/fixme_my_friend/hw07_file_copying/filecopy"
+)
+
+// barProgress prints copying message and shows progress bar in terminal.
+type barProgress struct {
+	out io.Writer
+	bar *pb.ProgressBar
+}
+
+// newBarProgress creates progress writing to stdout,
+// stdout may be used for data, so stderr is used in this case.
+func newBarProgress(stdoutUsed bool) *barProgress {
+	if stdoutUsed {
+		return &barProgress{out: os.Stderr}
+	}
+	return &barProgress{out: os.Stdout}
+}
+
+func (p *barProgress) Start(task filecopy.Task) {
+	fmt.Fprintln(p.out, getMessage(task))
+	if task.Total == filecopy.UnknownSize {
+		p.bar = pb.New64(0) // indeterminate progress
+		p.bar.ShowPercent = false
+	} else {
+		p.bar = pb.New64(task.Total)
+	}
+	p.bar.SetUnits(pb.U_BYTES)
+	p.bar.Output = p.out
+	p.bar.Start()
+}
+
+func (p *barProgress) Add(n int64) {
+	p.bar.Add64(n)
+}
+
+func (p *barProgress) Finish() {
+	p.bar.Finish()
+}
+
+var _ filecopy.Progress = (*barProgress)(nil)
@@ -21,5 +21,44 @@ cmp out.txt testdata/out_offset100_limit1000.txt
 ./go-cp -from testdata/input.txt -to out.txt -offset 6000 -limit 1000
 cmp out.txt testdata/out_offset6000_limit1000.txt
 
-rm -f go-cp out.txt
+head -c 3000 testdata/input.txt > out.txt.part
+./go-cp -from testdata/input.txt -to out.txt -resume
+cmp out.txt testdata/out_offset0_limit0.txt
+
+head -c 500 testdata/out_offset100_limit1000.txt > out.txt
+./go-cp -from testdata/input.txt -to out.txt -offset 100 -limit 1000 -resume
+cmp out.txt testdata/out_offset100_limit1000.txt
+
+cat testdata/input.txt | ./go-cp -from - -to - -offset 100 -limit 1000 > out.txt
+cmp out.txt testdata/out_offset100_limit1000.txt
+
+./go-cp -from /dev/urandom -to out.txt -limit 1000
+[ "$(wc -c < out.txt)" -eq 1000 ]
+
+./go-cp -from testdata/input.txt -to out.txt -verify crc32c -offset 100 -limit 1000
+cmp out.txt testdata/out_offset100_limit1000.txt
+
+./go-cp -from testdata/input.txt -to out.txt -sidecar write
+sha256sum -c out.txt.sha256
+
+./go-cp -from testdata/input.txt -to out.txt -offset 100 -limit 1000 -parallel 4 -chunk-size 100
+cmp out.txt testdata/out_offset100_limit1000.txt
+
+truncate -s 8M sparse.img
+echo data | dd of=sparse.img conv=notrunc status=none
+./go-cp -from sparse.img -to out.img -sparse
+cmp sparse.img out.img
+[ "$(du -k out.img | cut -f1)" -lt 1024 ]
+
+./go-cp -from testdata/input.txt -to out.gz -compress gzip
+gunzip -c out.gz | cmp - testdata/input.txt
+./go-cp -from out.gz -to out.txt -decompress auto
+cmp out.txt testdata/input.txt
+
+rm -rf out_dir
+./go-cp -from testdata -to out_dir -recursive -exclude 'out_*'
+diff -r <(ls testdata | grep -v '^out_') <(ls out_dir)
+cmp out_dir/input.txt testdata/input.txt
+
+rm -rf go-cp out.txt out.gz out.txt.sha256 out_dir sparse.img out.img
 echo "PASS"
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrInvalidEnvFile = errors.New("invalid env file")
+
+// ReadEnvFile reads a .env file and returns map of env variables.
+// Each line is KEY=value, optionally prefixed by export. Values can be quoted:
+// single quotes keep value as is, double quotes support escapes (\n, \t, \", \\, \$).
+// Quoted values can span several lines. Lines and unquoted value tails starting with # are comments.
+// ${VAR} in unquoted and double-quoted values is replaced by variable defined above in the file
+// or by variable of the current environment.
+func ReadEnvFile(path string) (Environment, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := envParser{src: string(content), envs: Environment{}, lookup: os.LookupEnv}
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("%s:%d: %w", path, p.line(), err)
+	}
+	return p.envs, nil
+}
+
+// envParser parses content of .env file.
+type envParser struct {
+	src    string
+	pos    int
+	envs   Environment
+	lookup func(string) (string, bool)
+}
+
+func (p *envParser) parse() error {
+	for {
+		p.skipSpaces()
+		switch {
+		case p.eof():
+			return nil
+		case p.peek() == '\n':
+			p.pos++
+		case p.peek() == '#':
+			p.skipComment()
+		default:
+			if err := p.parseAssignment(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseAssignment parses one KEY=value definition.
+func (p *envParser) parseAssignment() error {
+	key := p.readKey()
+	if key == "export" && !p.eof() && isSpace(p.peek()) {
+		p.skipSpaces()
+		key = p.readKey()
+	}
+	if key == "" {
+		return fmt.Errorf("%w: variable name expected", ErrInvalidEnvFile)
+	}
+	p.skipSpaces()
+	if p.eof() || p.peek() != '=' {
+		return fmt.Errorf("%w: = expected after %s", ErrInvalidEnvFile, key)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	var value string
+	var err error
+	switch {
+	case p.eof():
+	case p.peek() == '\'':
+		value, err = p.readSingleQuoted()
+	case p.peek() == '"':
+		value, err = p.readDoubleQuoted()
+	default:
+		value, err = p.readUnquoted()
+	}
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+	if !p.eof() && p.peek() == '#' {
+		p.skipComment()
+	}
+	if !p.eof() && p.peek() != '\n' {
+		return fmt.Errorf("%w: unexpected text after value of %s", ErrInvalidEnvFile, key)
+	}
+	p.envs[key] = EnvValue{Value: value}
+	return nil
+}
+
+func (p *envParser) readKey() string {
+	start := p.pos
+	for !p.eof() && isKeyChar(p.peek()) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *envParser) readSingleQuoted() (string, error) {
+	p.pos++
+	end := strings.IndexByte(p.src[p.pos:], '\'')
+	if end < 0 {
+		return "", fmt.Errorf("%w: unterminated quote", ErrInvalidEnvFile)
+	}
+	value := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return value, nil
+}
+
+func (p *envParser) readDoubleQuoted() (string, error) {
+	p.pos++
+	sb := strings.Builder{}
+	for !p.eof() {
+		ch := p.peek()
+		switch ch {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			p.pos++
+			if p.eof() {
+				return "", fmt.Errorf("%w: unterminated quote", ErrInvalidEnvFile)
+			}
+			sb.WriteString(unescape(p.peek()))
+			p.pos++
+		case '$':
+			if err := p.expand(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(ch)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("%w: unterminated quote", ErrInvalidEnvFile)
+}
+
+// readUnquoted reads value until end of line or comment, trailing spaces are removed.
+func (p *envParser) readUnquoted() (string, error) {
+	sb := strings.Builder{}
+	for !p.eof() && p.peek() != '\n' {
+		ch := p.peek()
+		if ch == '#' && isSpace(p.src[p.pos-1]) {
+			break
+		}
+		if ch == '$' {
+			if err := p.expand(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		sb.WriteByte(ch)
+		p.pos++
+	}
+	return strings.TrimRight(sb.String(), " \t\r"), nil
+}
+
+// expand writes value of ${VAR} reference at current position, other $ are kept as is.
+func (p *envParser) expand(sb *strings.Builder) error {
+	if !strings.HasPrefix(p.src[p.pos:], "${") {
+		sb.WriteByte('$')
+		p.pos++
+		return nil
+	}
+	end := strings.IndexByte(p.src[p.pos:], '}')
+	if end < 0 {
+		return fmt.Errorf("%w: unterminated variable reference", ErrInvalidEnvFile)
+	}
+	name := p.src[p.pos+2 : p.pos+end]
+	p.pos += end + 1
+	if val, ok := p.envs[name]; ok {
+		sb.WriteString(val.Value)
+		return nil
+	}
+	if val, ok := p.lookup(name); ok {
+		sb.WriteString(val)
+	}
+	return nil
+}
+
+func (p *envParser) skipSpaces() {
+	for !p.eof() && isSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *envParser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *envParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *envParser) peek() byte {
+	return p.src[p.pos]
+}
+
+// line returns number of line at current position for error messages.
+func (p *envParser) line() int {
+	return strings.Count(p.src[:p.pos], "\n") + 1
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\r'
+}
+
+func isKeyChar(ch byte) bool {
+	return ch == '_' || ch == '.' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// unescape returns character of escape sequence in double-quoted value.
+func unescape(ch byte) string {
+	switch ch {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case 'r':
+		return "\r"
+	case '"', '\\', '$':
+		return string(ch)
+	default:
+		return "\\" + string(ch)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestReadEnvFile(t *testing.T) {
+	os.Setenv("FROM_ENV", "env value")
+	defer os.Unsetenv("FROM_ENV")
+	cases := []struct {
+		name    string
+		content string
+		exp     Environment
+	}{
+		{"empty", "", Environment{}},
+		{"comments", "# comment\n\n  # indented comment\nA=1\n", Environment{"A": {Value: "1"}}},
+		{"spaces", "  A = 1 2 \t\r\nB=", Environment{"A": {Value: "1 2"}, "B": {Value: ""}}},
+		{"export", "export A=1\nexport=2", Environment{"A": {Value: "1"}, "export": {Value: "2"}}},
+		{"inline comment", "A=1 # comment\nB=x#y\nC= # empty", Environment{
+			"A": {Value: "1"}, "B": {Value: "x#y"}, "C": {Value: ""},
+		}},
+		{"single quotes", "A='${FROM_ENV} \\n # x'\nB='multi\nline' # comment", Environment{
+			"A": {Value: "${FROM_ENV} \\n # x"}, "B": {Value: "multi\nline"},
+		}},
+		{"double quotes", `A="say \"hi\"\t\\ \$HOME \q"` + "\nB=\"multi\nline\"", Environment{
+			"A": {Value: "say \"hi\"\t\\ $HOME \\q"}, "B": {Value: "multi\nline"},
+		}},
+		{"interpolation", "A=a\nB=${A}-${FROM_ENV}-${MISSING}\nC=\"${B}\"\nD=$A", Environment{
+			"A": {Value: "a"}, "B": {Value: "a-env value-"}, "C": {Value: "a-env value-"}, "D": {Value: "$A"},
+		}},
+		{"override", "A=1\nA=2", Environment{"A": {Value: "2"}}},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := ReadEnvFile(writeEnvFile(t, tc.content))
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, res)
+		})
+	}
+}
+
+func TestReadEnvFileError(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		line    string
+	}{
+		{"no assignment", "A=1\nB\n", ":2:"},
+		{"no name", "=1", ":1:"},
+		{"invalid name", "A-B=1", ":1:"},
+		{"unterminated single quote", "A='1\n\n", ":1:"},
+		{"unterminated double quote", "A=\"1\\\"", ":1:"},
+		{"unterminated reference", "\nA=${B", ":2:"},
+		{"text after quotes", "A='1' 2", ":1:"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := ReadEnvFile(writeEnvFile(t, tc.content))
+			require.Nil(t, res)
+			require.ErrorIs(t, err, ErrInvalidEnvFile)
+			require.Contains(t, err.Error(), tc.line)
+		})
+	}
+
+	t.Run("file does not exist", func(t *testing.T) {
+		_, err := ReadEnvFile("hzhz.env")
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
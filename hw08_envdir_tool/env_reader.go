@@ -16,26 +16,110 @@ var (
 )
 
 // EnvValue helps to distinguish between empty files and files with the first empty line.
+// Secret is set for values read from secret files, they are redacted in output.
 type EnvValue struct {
 	Value      string
 	NeedRemove bool
+	Secret     bool
+}
+
+// ReadOption configures reading of env directory.
+type ReadOption func(*readOptions)
+
+type readOptions struct {
+	fullValue    bool
+	namespaces   bool
+	secretFiles  bool
+	forceSecrets bool
+}
+
+// WithFullValue makes the whole file content a value instead of the first line.
+// Terminal zeros are replaced by new lines as in the first line, the last new line is removed.
+func WithFullValue() ReadOption {
+	return func(o *readOptions) {
+		o.fullValue = true
+	}
+}
+
+// WithNamespaces makes subdirectories prefixed namespaces of variables, e.g. file db/host is variable DB_HOST.
+// Names of nested variables are upper-cased, characters other than letters and digits are replaced by _.
+// Without this option subdirectories are skipped.
+func WithNamespaces() ReadOption {
+	return func(o *readOptions) {
+		o.namespaces = true
+	}
 }
 
 // ReadDir reads a specified directory and returns map of env variables.
 // Variables represented as files where filename is name of variable, file first line is a value.
-func ReadDir(dir string) (Environment, error) {
+func ReadDir(dir string, opts ...ReadOption) (Environment, error) {
+	options := readOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
 	stat, err := os.Stat(dir)
 	if os.IsNotExist(err) {
 		return nil, fmt.Errorf("%s: %w", dir, ErrDirDoesNotExist)
 	}
+	if err != nil {
+		return nil, err
+	}
 	if !stat.IsDir() {
 		return nil, fmt.Errorf("%s: %w", dir, ErrIsNotDir)
 	}
-	files, err1 := os.ReadDir(dir)
-	if err1 != nil {
-		return nil, fmt.Errorf("%s: %w", dir, err1)
+	envs := Environment{}
+	if err := readDirInto(envs, dir, "", []os.FileInfo{stat}, options); err != nil {
+		return nil, err
+	}
+	if err := resolveSecrets(envs, options); err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	return envs, nil
+}
+
+// ReadDirs reads several env directories or .env files, variables of later ones override earlier ones.
+// Empty file in later directory removes variable defined in earlier one.
+func ReadDirs(dirs []string, opts ...ReadOption) (Environment, error) {
+	options := readOptions{}
+	for _, opt := range opts {
+		opt(&options)
 	}
 	envs := Environment{}
+	for _, dir := range dirs {
+		layer, err := readLayer(dir, options, opts)
+		if err != nil {
+			return nil, err
+		}
+		for name, val := range layer {
+			envs[name] = val
+		}
+	}
+	return envs, nil
+}
+
+// readLayer reads env directory or .env file.
+func readLayer(path string, options readOptions, opts []ReadOption) (Environment, error) {
+	stat, err := os.Stat(path)
+	if err != nil || !stat.Mode().IsRegular() {
+		return ReadDir(path, opts...)
+	}
+	envs, err := ReadEnvFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveSecrets(envs, options); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return envs, nil
+}
+
+// readDirInto adds variables of dir to envs, names of variables get prefix.
+// Parents are directories being read, they are used to detect loops of symlinks.
+func readDirInto(envs Environment, dir, prefix string, parents []os.FileInfo, options readOptions) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dir, err)
+	}
 	for _, file := range files {
 		if strings.Contains(file.Name(), "=") {
 			continue
@@ -47,26 +131,72 @@ func ReadDir(dir string) (Environment, error) {
 		)
 		stat, err := os.Stat(fullName)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		name := file.Name()
+		if prefix != "" {
+			name = prefix + namespaceName(name)
 		}
 		if stat.IsDir() {
+			if !options.namespaces || isParent(stat, parents) {
+				continue
+			}
+			nested := prefix + namespaceName(file.Name()) + "_"
+			err := readDirInto(envs, fullName, nested, append(parents, stat), options)
+			if err != nil {
+				return err
+			}
 			continue
 		}
 		if stat.Size() == 0 {
-			envs[file.Name()] = EnvValue{
+			envs[name] = EnvValue{
 				NeedRemove: true,
 			}
 			continue
 		}
+		if options.fullValue {
+			content, err := os.ReadFile(fullName)
+			if err != nil {
+				return err
+			}
+			envs[name] = EnvValue{
+				Value: clearFullString(string(content)),
+			}
+			continue
+		}
 		fl, err := readFirstLine(fullName)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		envs[file.Name()] = EnvValue{
+		envs[name] = EnvValue{
 			Value: clearString(fl),
 		}
 	}
-	return envs, nil
+	return nil
+}
+
+// namespaceName converts name of nested file or directory to name of variable.
+func namespaceName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// isParent reports if dir is one of parents, so reading it leads to a loop.
+func isParent(dir os.FileInfo, parents []os.FileInfo) bool {
+	for _, parent := range parents {
+		if os.SameFile(dir, parent) {
+			return true
+		}
+	}
+	return false
 }
 
 // readFirstLine reads first line only from file given by fileName.
@@ -78,7 +208,7 @@ func readFirstLine(fileName string) (string, error) {
 	defer fp.Close()
 	con, err1 := io.ReadAll(fp)
 	if err1 != nil {
-		return "", err
+		return "", err1
 	}
 	return strings.Split(string(con), "\n")[0], nil
 }
@@ -89,3 +219,9 @@ func clearString(s string) string {
 	ret = strings.TrimRight(ret, "\t ")
 	return ret
 }
+
+// clearFullString prepares multi-line file content from usage.
+func clearFullString(s string) string {
+	ret := strings.ReplaceAll(s, "\x00", "\n")
+	return strings.TrimSuffix(ret, "\n")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -36,6 +37,71 @@ func TestReadDir(t *testing.T) {
 	}
 }
 
+func TestReadDirFullValue(t *testing.T) {
+	res, err := ReadDir("./testdata/env", WithFullValue())
+	require.NoError(t, err)
+	exp := Environment{
+		"BAR":   EnvValue{Value: "bar\nPLEASE IGNORE SECOND LINE"},
+		"EMPTY": EnvValue{Value: " "},
+		"FOO":   EnvValue{Value: "   foo\nwith new line"},
+		"HELLO": EnvValue{Value: "\"hello\""},
+		"UNSET": EnvValue{NeedRemove: true},
+	}
+	require.Equal(t, exp, res)
+}
+
+func TestReadDirNamespaces(t *testing.T) {
+	res, err := ReadDir("./testdata/layers/prod")
+	require.NoError(t, err)
+	require.Equal(t, Environment{
+		"DEBUG":     EnvValue{NeedRemove: true},
+		"LOG_LEVEL": EnvValue{Value: "warn"},
+	}, res)
+
+	res, err = ReadDir("./testdata/layers/prod", WithNamespaces())
+	require.NoError(t, err)
+	require.Equal(t, Environment{
+		"DEBUG":           EnvValue{NeedRemove: true},
+		"LOG_LEVEL":       EnvValue{Value: "warn"},
+		"DB_HOST":         EnvValue{Value: "db.example.com"},
+		"DB_PASS_WORD":    EnvValue{Value: "it's secret"},
+		"DB_REPLICA_HOST": EnvValue{Value: "replica.example.com"},
+	}, res)
+
+	t.Run("symlink loop", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "a"), []byte("1"), 0o644))
+		require.NoError(t, os.Symlink("..", filepath.Join(dir, "sub", "loop")))
+		res, err := ReadDir(dir, WithNamespaces())
+		require.NoError(t, err)
+		require.Equal(t, Environment{"SUB_A": EnvValue{Value: "1"}}, res)
+	})
+}
+
+func TestReadDirs(t *testing.T) {
+	dirs := []string{"./testdata/layers/defaults", "./testdata/layers/prod"}
+	res, err := ReadDirs(dirs, WithNamespaces())
+	require.NoError(t, err)
+	require.Equal(t, Environment{
+		"DEBUG":           EnvValue{NeedRemove: true},
+		"LOG_LEVEL":       EnvValue{Value: "warn"},
+		"DB_HOST":         EnvValue{Value: "db.example.com"},
+		"DB_PORT":         EnvValue{Value: "5432"},
+		"DB_PASS_WORD":    EnvValue{Value: "it's secret"},
+		"DB_REPLICA_HOST": EnvValue{Value: "replica.example.com"},
+	}, res)
+
+	res, err = ReadDirs([]string{"./testdata/layers/prod", "./testdata/app.env"})
+	require.NoError(t, err)
+	require.Equal(t, EnvValue{Value: "warn"}, res["LOG_LEVEL"])
+	require.Equal(t, EnvValue{Value: "bar"}, res["BAR"])
+
+	res, err = ReadDirs([]string{"./testdata/layers/prod", "hzhz"})
+	require.Nil(t, res)
+	require.ErrorIs(t, err, ErrDirDoesNotExist)
+}
+
 func TestReadDirError(t *testing.T) {
 	t.Run("err dir does not exist", func(t *testing.T) {
 		res, err := ReadDir("hzhz")
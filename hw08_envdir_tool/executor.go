@@ -2,32 +2,185 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
 )
 
+var ErrUnknownSignal = errors.New("unknown signal")
+
+// Exit codes follow shell conventions.
+const (
+	codeFailure       = 1
+	codeNotExecutable = 126
+	codeNotFound      = 127
+	codeSignalBase    = 128
+)
+
+// RunOption configures running of command.
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	clean  bool
+	exec   bool
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// WithCleanEnv starts command with variables from env only, the current environment is not inherited.
+func WithCleanEnv() RunOption {
+	return func(o *runOptions) {
+		o.clean = true
+	}
+}
+
+// WithExec replaces the current process by command like daemontools envdir does,
+// RunCmd returns only if command can not be executed. Standard streams are not replaced in this mode.
+func WithExec() RunOption {
+	return func(o *runOptions) {
+		o.exec = true
+	}
+}
+
+// WithStdio replaces standard streams of command, by default streams of the current process are used.
+func WithStdio(stdin io.Reader, stdout, stderr io.Writer) RunOption {
+	return func(o *runOptions) {
+		o.stdin = stdin
+		o.stdout = stdout
+		o.stderr = stderr
+	}
+}
+
 // RunCmd runs a command + arguments (cmd) with environment variables from env.
-func RunCmd(cmd []string, env Environment) (returnCode int) {
+// Environment of the current process is not changed, so RunCmd can be called concurrently.
+// Signals received while command is running are forwarded to it. Return code is exit code of command,
+// 128+N if command is killed by signal N, 126 if command is not executable and 127 if it is not found.
+func RunCmd(cmd []string, env Environment, opts ...RunOption) (returnCode int) {
 	if len(cmd) == 0 {
 		return 0
 	}
-	for name, val := range env {
-		if val.NeedRemove {
-			os.Unsetenv(name)
+	options := newRunOptions(opts)
+	if options.exec {
+		return execCmd(cmd, options.environ(env), options.stderr)
+	}
+	c := options.command(cmd, env)
+
+	// signals are caught before start, so they are not lost while command is starting
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, forwardedSignals...)
+	defer signal.Stop(sigCh)
+	if err := c.Start(); err != nil {
+		return startErrorCode(err, options.stderr)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go forwardSignals(sigCh, c.Process, done)
+	return exitCode(c.Wait(), options.stderr)
+}
+
+func newRunOptions(opts []RunOption) runOptions {
+	options := runOptions{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// environ returns environment of command.
+func (o runOptions) environ(env Environment) []string {
+	var base []string
+	if !o.clean {
+		base = os.Environ()
+	}
+	return MergeEnv(base, env)
+}
+
+// command creates command with environment and standard streams.
+func (o runOptions) command(cmd []string, env Environment) *exec.Cmd {
+	c := exec.Command(cmd[0], cmd[1:]...) //nolint:gosec
+	c.Env = o.environ(env)
+	c.Stdin = o.stdin
+	c.Stdout = o.stdout
+	c.Stderr = o.stderr
+	return c
+}
+
+// forwardSignals sends signals from sigCh to process until done is closed.
+func forwardSignals(sigCh <-chan os.Signal, p *os.Process, done <-chan struct{}) {
+	for {
+		select {
+		case sig := <-sigCh:
+			_ = p.Signal(sig)
+		case <-done:
+			return
+		}
+	}
+}
+
+// ParseSignal returns signal by name, e.g. TERM or SIGTERM.
+func ParseSignal(name string) (os.Signal, error) {
+	sig, ok := signalNames[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSignal, name)
+	}
+	return sig, nil
+}
+
+// startErrorCode reports error of starting command and returns shell exit code for it.
+func startErrorCode(err error, stderr io.Writer) int {
+	fmt.Fprintf(stderr, "go-envdir: %v\n", err)
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return codeNotFound
+	}
+	return codeNotExecutable
+}
+
+// exitCode returns exit code of finished command.
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		fmt.Fprintf(stderr, "go-envdir: %v\n", err)
+		return codeFailure
+	}
+	if sig, ok := exitSignal(exitErr.ProcessState); ok {
+		return codeSignalBase + sig
+	}
+	return exitErr.ExitCode()
+}
+
+// MergeEnv returns environment base (list of key=value) with variables from env.
+// Variables of env replace variables of base with the same name, variables with NeedRemove are removed.
+// Order of base is kept, variables of env are appended sorted by name.
+func MergeEnv(base []string, env Environment) []string {
+	merged := make([]string, 0, len(base)+len(env))
+	for _, kv := range base {
+		name := kv
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			name = kv[:i]
+		}
+		if _, ok := env[name]; ok {
 			continue
 		}
-		os.Setenv(name, val.Value)
+		merged = append(merged, kv)
 	}
-	c := exec.Command(cmd[0], cmd[1:]...) //nolint:gosec
-	c.Env = os.Environ()
-	c.Stdin = os.Stdin
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
-	if err := c.Run(); err != nil {
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			return err.(*exec.ExitError).ExitCode() //nolint:errorlint
+	names := make([]string, 0, len(env))
+	for name, val := range env {
+		if !val.NeedRemove {
+			names = append(names, name)
 		}
 	}
-	return 0
+	sort.Strings(names)
+	for _, name := range names {
+		merged = append(merged, name+"="+env[name].Value)
+	}
+	return merged
 }
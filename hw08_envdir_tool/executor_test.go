@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"io"
 	"os"
+	"strconv"
+	"sync"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -41,3 +45,106 @@ func TestErrorRunCmd(t *testing.T) {
 	code := RunCmd([]string{"ls", "hzhz"}, make(Environment))
 	require.NotEqual(t, 0, code)
 }
+
+func TestRunCmdKeepsEnvironment(t *testing.T) {
+	os.Setenv("HELLO", "hzhz")
+	defer os.Unsetenv("HELLO")
+	before := os.Environ()
+	env := Environment{
+		"HELLO": EnvValue{Value: "hello"},
+		"HOME":  EnvValue{NeedRemove: true},
+	}
+	code := RunCmd([]string{"true"}, env, WithStdio(nil, io.Discard, io.Discard))
+	require.Equal(t, 0, code)
+	require.Equal(t, before, os.Environ())
+}
+
+func TestRunCmdClean(t *testing.T) {
+	os.Setenv("ADDED", "from original env")
+	defer os.Unsetenv("ADDED")
+	out := &bytes.Buffer{}
+	env := Environment{"BAR": EnvValue{Value: "bar"}}
+	code := RunCmd([]string{"env"}, env, WithCleanEnv(), WithStdio(nil, out, io.Discard))
+	require.Equal(t, 0, code)
+	require.Equal(t, "BAR=bar\n", out.String())
+}
+
+func TestRunCmdConcurrent(t *testing.T) {
+	wg := sync.WaitGroup{}
+	outs := make([]bytes.Buffer, 10)
+	for i := range outs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			env := Environment{"N": EnvValue{Value: strconv.Itoa(i)}}
+			RunCmd([]string{"sh", "-c", "echo $N"}, env, WithStdio(nil, &outs[i], io.Discard))
+		}(i)
+	}
+	wg.Wait()
+	for i := range outs {
+		require.Equal(t, strconv.Itoa(i)+"\n", outs[i].String())
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	cases := []struct {
+		name string
+		base []string
+		env  Environment
+		exp  []string
+	}{
+		{"empty", nil, Environment{}, []string{}},
+		{"inherit", []string{"A=1", "B=2"}, Environment{}, []string{"A=1", "B=2"}},
+		{
+			"replace and add", []string{"B=2", "A=1", "C=3"},
+			Environment{"A": EnvValue{Value: "x"}, "D": EnvValue{Value: "y=z"}},
+			[]string{"B=2", "C=3", "A=x", "D=y=z"},
+		},
+		{
+			"remove", []string{"A=1", "B=2"},
+			Environment{"A": EnvValue{NeedRemove: true}, "X": EnvValue{NeedRemove: true}},
+			[]string{"B=2"},
+		},
+		{"empty value", []string{"A=1"}, Environment{"A": EnvValue{}}, []string{"A="}},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.exp, MergeEnv(tc.base, tc.env))
+		})
+	}
+}
+
+func TestRunCmdExitCode(t *testing.T) {
+	cases := []struct {
+		name string
+		cmd  []string
+		code int
+	}{
+		{"success", []string{"true"}, 0},
+		{"exit code", []string{"sh", "-c", "exit 3"}, 3},
+		{"not found in path", []string{"hzhz-not-found"}, 127},
+		{"file not found", []string{"./testdata/hzhz"}, 127},
+		{"not executable", []string{"./testdata/env/BAR"}, 126},
+		{"directory", []string{"./testdata"}, 126},
+		{"killed by signal", []string{"sh", "-c", "kill -TERM $$"}, 128 + int(syscall.SIGTERM)},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			code := RunCmd(tc.cmd, Environment{}, WithStdio(nil, io.Discard, stderr))
+			require.Equal(t, tc.code, code)
+			if code >= 126 && code <= 127 {
+				require.Contains(t, stderr.String(), "go-envdir:")
+			}
+		})
+	}
+
+	t.Run("exec mode errors", func(t *testing.T) {
+		code := RunCmd([]string{"hzhz-not-found"}, Environment{}, WithExec(), WithStdio(nil, nil, io.Discard))
+		require.Equal(t, 127, code)
+		code = RunCmd([]string{"./testdata/env/BAR"}, Environment{}, WithExec(), WithStdio(nil, nil, io.Discard))
+		require.Equal(t, 126, code)
+	})
+}
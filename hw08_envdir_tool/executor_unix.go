@@ -0,0 +1,42 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+var forwardedSignals = []os.Signal{
+	syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGUSR1, syscall.SIGUSR2,
+}
+
+// DefaultStopSignal asks command to exit gracefully.
+var DefaultStopSignal os.Signal = syscall.SIGTERM
+
+var signalNames = map[string]os.Signal{
+	"INT": syscall.SIGINT, "TERM": syscall.SIGTERM, "HUP": syscall.SIGHUP, "QUIT": syscall.SIGQUIT,
+	"USR1": syscall.SIGUSR1, "USR2": syscall.SIGUSR2, "KILL": syscall.SIGKILL,
+}
+
+// execCmd replaces the current process by command, it returns only if command can not be executed.
+func execCmd(cmd []string, env []string, stderr io.Writer) int {
+	path, err := exec.LookPath(cmd[0])
+	if err != nil {
+		return startErrorCode(err, stderr)
+	}
+	err = syscall.Exec(path, cmd, env) //nolint:gosec
+	return startErrorCode(&os.PathError{Op: "exec", Path: path, Err: err}, stderr)
+}
+
+// exitSignal returns number of signal which killed process.
+func exitSignal(state *os.ProcessState) (int, bool) {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return 0, false
+	}
+	return int(status.Signal()), true
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCmdForwardsSignals(t *testing.T) {
+	pr, pw := io.Pipe()
+	codeCh := make(chan int, 1)
+	go func() {
+		script := `trap 'exit 42' USR1; echo ready; while :; do sleep 0.05; done`
+		codeCh <- RunCmd([]string{"sh", "-c", script}, Environment{}, WithStdio(nil, pw, io.Discard))
+		pw.Close()
+	}()
+	line, err := bufio.NewReader(pr).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "ready\n", line)
+	go io.Copy(io.Discard, pr)
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	select {
+	case code := <-codeCh:
+		require.Equal(t, 42, code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal is not forwarded")
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+var ErrExecNotSupported = errors.New("exec mode is not supported")
+
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// DefaultStopSignal asks command to exit, only killing is supported.
+var DefaultStopSignal = os.Kill
+
+var signalNames = map[string]os.Signal{"INT": os.Interrupt, "KILL": os.Kill}
+
+// execCmd reports that process can not be replaced.
+func execCmd(_, _ []string, stderr io.Writer) int {
+	return startErrorCode(ErrExecNotSupported, stderr)
+}
+
+// exitSignal returns false, process can not be killed by signal.
+func exitSignal(*os.ProcessState) (int, bool) {
+	return 0, false
+}
@@ -1,21 +1,128 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
+var (
+	fullValue, namespaces bool
+	secrets, forceSecrets bool
+	clean, execMode       bool
+	printFormat           string
+	redact                string
+	watch                 bool
+	stopSignal            string
+	stopTimeout, debounce time.Duration
+)
+
+func init() {
+	flag.BoolVar(&fullValue, "full", false, "use the whole file content as a value instead of the first line")
+	flag.BoolVar(&namespaces, "namespaces", false, "read subdirectories as prefixed namespaces, e.g. db/host is DB_HOST")
+	flag.BoolVar(&secrets, "secrets", false, "read values starting with @ from files, e.g. @/run/secrets/db")
+	flag.BoolVar(&forceSecrets, "force", false, "allow secret files readable by group or others")
+	flag.StringVar(&printFormat, "print", "", "print merged variables instead of running command: export or json")
+	flag.StringVar(&redact, "redact", strings.Join(DefaultRedactPatterns, ","),
+		"comma-separated glob patterns of names which values are redacted in printed output")
+	flag.BoolVar(&clean, "clean", false, "do not inherit the current environment")
+	flag.BoolVar(&clean, "i", false, "shorthand for -clean")
+	flag.BoolVar(&execMode, "exec", false, "replace go-envdir process by command instead of running it as a child")
+	flag.BoolVar(&watch, "watch", false, "restart command when env files are changed")
+	flag.StringVar(&stopSignal, "stop-signal", "TERM", "signal stopping command before restart in watch mode")
+	flag.DurationVar(&stopTimeout, "stop-timeout", DefaultStopTimeout,
+		"time to wait for command after stop signal before killing it in watch mode")
+	flag.DurationVar(&debounce, "debounce", DefaultDebounce, "time without changes before restart in watch mode")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage: %s [flags] /path/to/env/dir|/path/to/.env[%c...] command arg1 arg2\n"+
+				"Variables of later directories override earlier ones.\n", os.Args[0], os.PathListSeparator)
+		flag.PrintDefaults()
+	}
+}
+
 func main() {
-	args := os.Args
-	if len(args) < 2 {
-		fmt.Printf("Usage: %s /path/to/env/dir command arg1 arg2\n", args[0])
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 1 {
+		flag.Usage()
 		return
 	}
-	dir := args[1]
-	cmds := args[2:]
-	envs, err := ReadDir(dir)
+	dirs := filepath.SplitList(args[0])
+	cmds := args[1:]
+	readOpts := readOptionsFromFlags()
+	if watch {
+		os.Exit(runWatcher(dirs, cmds, readOpts))
+	}
+	envs, err := ReadDirs(dirs, readOpts...)
+	if err != nil {
+		panic(err)
+	}
+	if printFormat != "" {
+		if err := PrintEnv(os.Stdout, Redact(envs, splitPatterns(redact)), printFormat); err != nil {
+			panic(err)
+		}
+		return
+	}
+	var opts []RunOption
+	if clean {
+		opts = append(opts, WithCleanEnv())
+	}
+	if execMode {
+		opts = append(opts, WithExec())
+	}
+	os.Exit(RunCmd(cmds, envs, opts...))
+}
+
+// runWatcher runs command in watch mode.
+func runWatcher(dirs, cmds []string, readOpts []ReadOption) int {
+	if execMode || printFormat != "" {
+		panic(errors.New("watch mode can not be used with -exec or -print"))
+	}
+	sig, err := ParseSignal(stopSignal)
 	if err != nil {
 		panic(err)
 	}
-	os.Exit(RunCmd(cmds, envs))
+	w := &Watcher{
+		Dirs:        dirs,
+		ReadOptions: readOpts,
+		StopSignal:  sig,
+		StopTimeout: stopTimeout,
+		Debounce:    debounce,
+	}
+	if clean {
+		w.RunOptions = append(w.RunOptions, WithCleanEnv())
+	}
+	return w.Run(context.Background(), cmds)
+}
+
+// readOptionsFromFlags returns options of reading env directories and files.
+func readOptionsFromFlags() []ReadOption {
+	var opts []ReadOption
+	if fullValue {
+		opts = append(opts, WithFullValue())
+	}
+	if namespaces {
+		opts = append(opts, WithNamespaces())
+	}
+	if secrets {
+		opts = append(opts, WithSecretFiles(forceSecrets))
+	}
+	return opts
+}
+
+// splitPatterns parses comma-separated list of patterns.
+func splitPatterns(list string) []string {
+	var patterns []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Formats of printed environment.
+const (
+	PrintExport = "export"
+	PrintJSON   = "json"
+)
+
+var ErrInvalidPrintFormat = errors.New("invalid print format")
+
+// PrintEnv writes env to w in format PrintExport or PrintJSON.
+// Export format is a shell script with export and unset lines sorted by name,
+// JSON format is an object where variables to remove are null.
+func PrintEnv(w io.Writer, env Environment, format string) error {
+	switch format {
+	case PrintExport:
+		return printExport(w, env)
+	case PrintJSON:
+		return printJSON(w, env)
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidPrintFormat, format)
+	}
+}
+
+func printExport(w io.Writer, env Environment) error {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var err error
+		if env[name].NeedRemove {
+			_, err = fmt.Fprintf(w, "unset %s\n", name)
+		} else {
+			_, err = fmt.Fprintf(w, "export %s=%s\n", name, shellQuote(env[name].Value))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printJSON(w io.Writer, env Environment) error {
+	values := make(map[string]*string, len(env))
+	for name, val := range env {
+		if val.NeedRemove {
+			values[name] = nil
+			continue
+		}
+		value := val.Value
+		values[name] = &value
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(values)
+}
+
+// shellQuote quotes s by single quotes for shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
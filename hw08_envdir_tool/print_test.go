@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintEnv(t *testing.T) {
+	env := Environment{
+		"B":     EnvValue{Value: "it's a \"value\"\nwith $HOME"},
+		"A":     EnvValue{Value: ""},
+		"UNSET": EnvValue{NeedRemove: true},
+	}
+
+	t.Run("export", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, PrintEnv(out, env, PrintExport))
+		require.Equal(t, "export A=''\nexport B='it'\\''s a \"value\"\nwith $HOME'\nunset UNSET\n", out.String())
+
+		// printed script restores values in shell
+		script := out.String() + `printf '%s|%s|%s' "$A" "$B" "${UNSET-unset}"`
+		res, err := exec.Command("sh", "-c", script).Output()
+		require.NoError(t, err)
+		require.Equal(t, "|"+env["B"].Value+"|unset", string(res))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, PrintEnv(out, env, PrintJSON))
+		require.JSONEq(t, `{"A": "", "B": "it's a \"value\"\nwith $HOME", "UNSET": null}`, out.String())
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		err := PrintEnv(&bytes.Buffer{}, env, "yaml")
+		require.ErrorIs(t, err, ErrInvalidPrintFormat)
+	})
+}
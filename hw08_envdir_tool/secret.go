@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SecretPrefix marks value which is a path to file with the real value, @@ escapes literal @.
+	SecretPrefix = "@"
+
+	// RedactedValue replaces values of secrets in output.
+	RedactedValue = "***"
+)
+
+// DefaultRedactPatterns are glob patterns of names of variables which values are redacted in output.
+var DefaultRedactPatterns = []string{"*PASSWORD*", "*SECRET*", "*TOKEN*", "*KEY*", "*CREDENTIAL*"}
+
+var (
+	ErrInsecureSecret = errors.New("secret file is readable by group or others")
+	ErrInvalidSecret  = errors.New("secret is not a regular file")
+)
+
+// WithSecretFiles makes values starting with @ references to files with secrets, e.g. @/run/secrets/db.
+// Secret files readable by group or others are refused unless force is set.
+func WithSecretFiles(force bool) ReadOption {
+	return func(o *readOptions) {
+		o.secretFiles = true
+		o.forceSecrets = force
+	}
+}
+
+// resolveSecrets replaces references to secret files in envs by contents of files.
+func resolveSecrets(envs Environment, options readOptions) error {
+	if !options.secretFiles {
+		return nil
+	}
+	for name, val := range envs {
+		if val.NeedRemove || !strings.HasPrefix(val.Value, SecretPrefix) {
+			continue
+		}
+		if strings.HasPrefix(val.Value, SecretPrefix+SecretPrefix) {
+			envs[name] = EnvValue{Value: val.Value[len(SecretPrefix):]}
+			continue
+		}
+		value, err := readSecret(val.Value[len(SecretPrefix):], options.forceSecrets)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		envs[name] = EnvValue{Value: value, Secret: true}
+	}
+	return nil
+}
+
+// readSecret reads value from secret file, the last new line is removed.
+func readSecret(path string, force bool) (string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !stat.Mode().IsRegular() {
+		return "", fmt.Errorf("%s: %w", path, ErrInvalidSecret)
+	}
+	if !force && stat.Mode().Perm()&0o044 != 0 {
+		return "", fmt.Errorf("%s: %w (mode %v)", path, ErrInsecureSecret, stat.Mode().Perm())
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return clearFullString(string(content)), nil
+}
+
+// Redact returns copy of env where values of secrets and of variables matching patterns are replaced
+// by RedactedValue. Patterns are globs matched against names case-insensitively.
+func Redact(env Environment, patterns []string) Environment {
+	redacted := make(Environment, len(env))
+	for name, val := range env {
+		if !val.NeedRemove && (val.Secret || matchName(name, patterns)) {
+			val.Value = RedactedValue
+		}
+		redacted[name] = val
+	}
+	return redacted
+}
+
+func matchName(name string, patterns []string) bool {
+	name = strings.ToUpper(name)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(strings.ToUpper(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
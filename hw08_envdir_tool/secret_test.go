@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDirSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secret, []byte("p@ss\nword\n"), 0o600))
+	envDir := filepath.Join(dir, "env")
+	require.NoError(t, os.Mkdir(envDir, 0o755))
+	files := map[string]string{
+		"DB_PASSWORD": "@" + secret,
+		"EMAIL":       "@@example.com",
+		"PLAIN":       "value",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(envDir, name), []byte(content), 0o644))
+	}
+
+	res, err := ReadDir(envDir)
+	require.NoError(t, err)
+	require.Equal(t, EnvValue{Value: "@" + secret}, res["DB_PASSWORD"])
+
+	res, err = ReadDir(envDir, WithSecretFiles(false))
+	require.NoError(t, err)
+	require.Equal(t, Environment{
+		"DB_PASSWORD": EnvValue{Value: "p@ss\nword", Secret: true},
+		"EMAIL":       EnvValue{Value: "@example.com"},
+		"PLAIN":       EnvValue{Value: "value"},
+	}, res)
+
+	t.Run("env file", func(t *testing.T) {
+		envFile := filepath.Join(dir, ".env")
+		require.NoError(t, os.WriteFile(envFile, []byte("TOKEN=@"+secret), 0o644))
+		res, err := ReadDirs([]string{envFile}, WithSecretFiles(false))
+		require.NoError(t, err)
+		require.Equal(t, Environment{"TOKEN": EnvValue{Value: "p@ss\nword", Secret: true}}, res)
+	})
+
+	t.Run("readable by others", func(t *testing.T) {
+		require.NoError(t, os.Chmod(secret, 0o640))
+		defer os.Chmod(secret, 0o600)
+		res, err := ReadDir(envDir, WithSecretFiles(false))
+		require.Nil(t, res)
+		require.ErrorIs(t, err, ErrInsecureSecret)
+		require.Contains(t, err.Error(), "DB_PASSWORD")
+		require.NotContains(t, err.Error(), "p@ss")
+
+		res, err = ReadDir(envDir, WithSecretFiles(true))
+		require.NoError(t, err)
+		require.Equal(t, "p@ss\nword", res["DB_PASSWORD"].Value)
+	})
+
+	t.Run("not a file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(envDir, "DB_PASSWORD"), []byte("@"+dir), 0o644))
+		_, err := ReadDir(envDir, WithSecretFiles(false))
+		require.ErrorIs(t, err, ErrInvalidSecret)
+
+		require.NoError(t, os.WriteFile(filepath.Join(envDir, "DB_PASSWORD"), []byte("@"+dir+"/hzhz"), 0o644))
+		_, err = ReadDir(envDir, WithSecretFiles(false))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestRedact(t *testing.T) {
+	env := Environment{
+		"DB_PASSWORD": EnvValue{Value: "password"},
+		"api_token":   EnvValue{Value: "token"},
+		"FROM_FILE":   EnvValue{Value: "secret", Secret: true},
+		"HOST":        EnvValue{Value: "localhost"},
+		"OLD_KEY":     EnvValue{NeedRemove: true},
+	}
+	res := Redact(env, DefaultRedactPatterns)
+	require.Equal(t, Environment{
+		"DB_PASSWORD": EnvValue{Value: RedactedValue},
+		"api_token":   EnvValue{Value: RedactedValue},
+		"FROM_FILE":   EnvValue{Value: RedactedValue, Secret: true},
+		"HOST":        EnvValue{Value: "localhost"},
+		"OLD_KEY":     EnvValue{NeedRemove: true},
+	}, res)
+	require.Equal(t, "password", env["DB_PASSWORD"].Value)
+
+	res = Redact(env, []string{"HOST"})
+	require.Equal(t, "password", res["DB_PASSWORD"].Value)
+	require.Equal(t, RedactedValue, res["HOST"].Value)
+	require.Equal(t, RedactedValue, res["FROM_FILE"].Value)
+}
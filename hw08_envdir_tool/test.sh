@@ -21,5 +21,108 @@ arguments are arg1=1 arg2=2'
 
 [ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
 
+result=$(./go-envdir -full "$(pwd)/testdata/env" "/bin/bash" "$(pwd)/testdata/echo.sh")
+expected='HELLO is ("hello")
+BAR is (bar
+PLEASE IGNORE SECOND LINE)
+FOO is (   foo
+with new line)
+UNSET is ()
+ADDED is (from original env)
+EMPTY is ( )
+arguments are '
+
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+result=$(./go-envdir "$(pwd)/testdata/app.env" "/bin/bash" "$(pwd)/testdata/echo.sh" arg1=1)
+expected='HELLO is ("hello")
+BAR is (bar)
+FOO is (   foo
+with new line)
+UNSET is ()
+ADDED is (from original env, bar)
+EMPTY is ()
+arguments are arg1=1'
+
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+result=$(./go-envdir -i "$(pwd)/testdata/env" /usr/bin/env)
+expected='BAR=bar
+EMPTY=
+FOO=   foo
+with new line
+HELLO="hello"'
+
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+result=$(./go-envdir -exec "$(pwd)/testdata/env" "/bin/bash" "$(pwd)/testdata/echo.sh" arg1=1 arg2=2)
+expected='HELLO is ("hello")
+BAR is (bar)
+FOO is (   foo
+with new line)
+UNSET is ()
+ADDED is (from original env)
+EMPTY is ()
+arguments are arg1=1 arg2=2'
+
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+code=0
+./go-envdir "$(pwd)/testdata/env" hzhz-not-found 2>/dev/null || code=$?
+[ "${code}" -eq 127 ]
+code=0
+./go-envdir "$(pwd)/testdata/env" /bin/sh -c 'kill -TERM $$' || code=$?
+[ "${code}" -eq 143 ]
+
+result=$(./go-envdir -namespaces -print export "$(pwd)/testdata/layers/defaults:$(pwd)/testdata/layers/prod")
+expected="export DB_HOST='db.example.com'
+export DB_PASS_WORD='it'\\''s secret'
+export DB_PORT='5432'
+export DB_REPLICA_HOST='replica.example.com'
+unset DEBUG
+export LOG_LEVEL='warn'"
+
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+result=$(./go-envdir -namespaces "testdata/layers/defaults:testdata/layers/prod" /bin/sh -c 'echo "${DB_HOST}:${DB_PORT}"')
+[ "${result}" = "db.example.com:5432" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+secrets=$(mktemp -d)
+echo "s3cret" > "${secrets}/db"
+chmod 600 "${secrets}/db"
+mkdir "${secrets}/env"
+echo "@${secrets}/db" > "${secrets}/env/DB_PASS"
+echo "user" > "${secrets}/env/DB_USER"
+echo "hunter2" > "${secrets}/env/API_TOKEN"
+
+result=$(./go-envdir -secrets "${secrets}/env" /bin/sh -c 'echo "${DB_PASS}"')
+[ "${result}" = "s3cret" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+result=$(./go-envdir -secrets -print export "${secrets}/env")
+expected="export API_TOKEN='***'
+export DB_PASS='***'
+export DB_USER='user'"
+[ "${result}" = "${expected}" ] || (echo -e "invalid output: ${result}" && exit 1)
+
+chmod 644 "${secrets}/db"
+if ./go-envdir -secrets "${secrets}/env" /bin/true 2>/dev/null; then
+	echo "insecure secret is accepted" && exit 1
+fi
+./go-envdir -secrets -force "${secrets}/env" /bin/true
+rm -rf "${secrets}"
+
+watched=$(mktemp -d)
+echo 1 > "${watched}/FOO"
+./go-envdir -watch -debounce 50ms "${watched}" /bin/sh -c \
+	"echo \"\${FOO}\" >> ${watched}.out; trap 'exit 0' TERM; while :; do sleep 0.1; done" &
+pid=$!
+sleep 1
+echo 2 > "${watched}/FOO"
+sleep 1
+kill -TERM "${pid}"
+wait "${pid}"
+[ "$(cat "${watched}.out")" = "$(printf '1\n2')" ] || (echo -e "invalid output: $(cat "${watched}.out")" && exit 1)
+rm -rf "${watched}" "${watched}.out"
+
 rm -f go-envdir
 echo "PASS"
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+const (
+	// DefaultStopTimeout is time given to command to exit after stop signal before it is killed.
+	DefaultStopTimeout = 10 * time.Second
+
+	// DefaultDebounce is time without changes after which env files are read again.
+	DefaultDebounce = 200 * time.Millisecond
+)
+
+var ErrWatchNotSupported = errors.New("watching is not supported on this platform")
+
+// notifier reports changes in watched directories.
+type notifier interface {
+	// add watches directory without subdirectories, if names are given only changes of these files are reported.
+	add(dir string, names ...string) error
+	// wait blocks until some changes happen, it returns error after close.
+	wait() error
+	close() error
+}
+
+// Watcher runs command and restarts it when variables in env directories are changed.
+type Watcher struct {
+	// Dirs are env directories or .env files read by ReadDirs.
+	Dirs        []string
+	ReadOptions []ReadOption
+	// RunOptions configure command, exec mode is ignored.
+	RunOptions []RunOption
+	// StopSignal asks command to exit before restart, DefaultStopSignal if nil.
+	StopSignal os.Signal
+	// StopTimeout is time to wait for command after StopSignal, it is killed after it. DefaultStopTimeout if zero.
+	StopTimeout time.Duration
+	// Debounce is time without changes after which env is read again, DefaultDebounce if zero.
+	Debounce time.Duration
+}
+
+// Run runs command and restarts it with new environment when files in Dirs are changed.
+// Command is restarted only if variables are changed, invalid env files are reported and ignored.
+// Run returns when command exits by itself or ctx is done, return code is as in RunCmd.
+func (w *Watcher) Run(ctx context.Context, cmd []string) int {
+	if len(cmd) == 0 {
+		return 0
+	}
+	options := newRunOptions(w.RunOptions)
+	env, err := ReadDirs(w.Dirs, w.ReadOptions...)
+	if err != nil {
+		fmt.Fprintf(options.stderr, "go-envdir: %v\n", err)
+		return codeFailure
+	}
+	n, err := newNotifier()
+	if err != nil {
+		fmt.Fprintf(options.stderr, "go-envdir: %v\n", err)
+		return codeFailure
+	}
+	defer n.close()
+	w.addWatches(n, options.stderr)
+	changes := make(chan struct{}, 1)
+	go func() {
+		for n.wait() == nil {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, forwardedSignals...)
+	defer signal.Stop(sigCh)
+	for {
+		c := options.command(cmd, env)
+		if err := c.Start(); err != nil {
+			return startErrorCode(err, options.stderr)
+		}
+		exited := make(chan error, 1)
+		go func() {
+			exited <- c.Wait()
+		}()
+		newEnv, code := w.supervise(ctx, c, exited, env, changes, sigCh, n, options.stderr)
+		if newEnv == nil {
+			return code
+		}
+		env = newEnv
+	}
+}
+
+// supervise waits until command exits or variables are changed.
+// Command is stopped and new variables are returned if they are changed, otherwise exit code is returned.
+func (w *Watcher) supervise(ctx context.Context, c *exec.Cmd, exited <-chan error, env Environment,
+	changes <-chan struct{}, sigCh <-chan os.Signal, n notifier, stderr io.Writer,
+) (Environment, int) {
+	var debounce <-chan time.Time
+	for {
+		select {
+		case err := <-exited:
+			return nil, exitCode(err, stderr)
+		case sig := <-sigCh:
+			_ = c.Process.Signal(sig)
+		case <-ctx.Done():
+			return nil, w.stop(c, exited, stderr)
+		case <-changes:
+			debounce = time.After(w.debounce())
+		case <-debounce:
+			debounce = nil
+			if w.namespaces() {
+				// new subdirectories are watched too
+				w.addWatches(n, stderr)
+			}
+			newEnv, err := ReadDirs(w.Dirs, w.ReadOptions...)
+			if err != nil {
+				fmt.Fprintf(stderr, "go-envdir: %v\n", err)
+				continue
+			}
+			if reflect.DeepEqual(newEnv, env) {
+				continue
+			}
+			w.stop(c, exited, stderr)
+			return newEnv, 0
+		}
+	}
+}
+
+// stop sends stop signal to command and kills it after timeout, exit code of command is returned.
+func (w *Watcher) stop(c *exec.Cmd, exited <-chan error, stderr io.Writer) int {
+	sig := w.StopSignal
+	if sig == nil {
+		sig = DefaultStopSignal
+	}
+	timeout := w.StopTimeout
+	if timeout <= 0 {
+		timeout = DefaultStopTimeout
+	}
+	_ = c.Process.Signal(sig)
+	select {
+	case err := <-exited:
+		return exitCode(err, stderr)
+	case <-time.After(timeout):
+		_ = c.Process.Kill()
+		return exitCode(<-exited, stderr)
+	}
+}
+
+func (w *Watcher) debounce() time.Duration {
+	if w.Debounce <= 0 {
+		return DefaultDebounce
+	}
+	return w.Debounce
+}
+
+// namespaces reports if subdirectories of env directories are read.
+func (w *Watcher) namespaces() bool {
+	options := readOptions{}
+	for _, opt := range w.ReadOptions {
+		opt(&options)
+	}
+	return options.namespaces
+}
+
+// addWatches watches env directories and .env files. Only the file is watched in directory of .env file,
+// subdirectories of env directories are watched with namespaces only.
+func (w *Watcher) addWatches(n notifier, stderr io.Writer) {
+	for _, dir := range w.Dirs {
+		var err error
+		if stat, statErr := os.Stat(dir); statErr == nil && stat.Mode().IsRegular() {
+			// file is watched by its directory to catch replacing by rename
+			err = n.add(filepath.Dir(dir), filepath.Base(dir))
+		} else if !w.namespaces() {
+			err = n.add(dir)
+		} else {
+			err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || !d.IsDir() {
+					return err
+				}
+				return n.add(path)
+			})
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "go-envdir: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyEvents = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_ATTRIB |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE_SELF
+
+// inotify watches directories by inotify, adding watch for directory twice is allowed.
+type inotify struct {
+	fd   int
+	file *os.File
+
+	mu sync.Mutex
+	// names of files reported for watch descriptor, all changes are reported if there is no entry
+	names map[int32]map[string]bool
+}
+
+func newNotifier() (notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	// non-blocking descriptor is handled by runtime poller, so close interrupts reading.
+	// File.Fd makes descriptor blocking, so it is kept separately.
+	return &inotify{fd: fd, file: os.NewFile(uintptr(fd), "inotify"), names: map[int32]map[string]bool{}}, nil
+}
+
+func (n *inotify) add(dir string, names ...string) error {
+	wd, err := syscall.InotifyAddWatch(n.fd, dir, inotifyEvents)
+	if err != nil {
+		return &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	filter, filtered := n.names[int32(wd)]
+	if len(names) == 0 || (filtered && filter == nil) {
+		// the whole directory is watched
+		n.names[int32(wd)] = nil
+		return nil
+	}
+	if filter == nil {
+		filter = map[string]bool{}
+		n.names[int32(wd)] = filter
+	}
+	for _, name := range names {
+		filter[name] = true
+	}
+	return nil
+}
+
+// wait reads events until one of them is about watched file or directory.
+func (n *inotify) wait() error {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		size, err := n.file.Read(buf)
+		if err != nil {
+			return err
+		}
+		if n.relevant(buf[:size]) {
+			return nil
+		}
+	}
+}
+
+// relevant reports if some of events in buf are not filtered out by names.
+func (n *inotify) relevant(buf []byte) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for len(buf) >= syscall.SizeofInotifyEvent {
+		event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[0])) //nolint:gosec // layout of kernel inotify_event
+		end := syscall.SizeofInotifyEvent + int(event.Len)
+		if end > len(buf) {
+			return true
+		}
+		name := string(bytes.TrimRight(buf[syscall.SizeofInotifyEvent:end], "\x00"))
+		buf = buf[end:]
+		filter := n.names[event.Wd]
+		// events of directory itself and overflow of queue are always reported
+		if filter == nil || name == "" || filter[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *inotify) close() error {
+	return n.file.Close()
+}
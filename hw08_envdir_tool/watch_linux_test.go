@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// watchedCmd runs watcher in background and provides lines of command output.
+type watchedCmd struct {
+	lines chan string
+	code  chan int
+}
+
+func startWatcher(ctx context.Context, t *testing.T, w *Watcher, script string) *watchedCmd {
+	t.Helper()
+	pr, pw := io.Pipe()
+	w.RunOptions = append(w.RunOptions, WithStdio(nil, pw, io.Discard))
+	wc := &watchedCmd{lines: make(chan string, 10), code: make(chan int, 1)}
+	go func() {
+		wc.code <- w.Run(ctx, []string{"sh", "-c", script})
+		pw.Close()
+	}()
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			wc.lines <- scanner.Text()
+		}
+		close(wc.lines)
+	}()
+	return wc
+}
+
+func (wc *watchedCmd) requireLine(t *testing.T, exp string) {
+	t.Helper()
+	select {
+	case line := <-wc.lines:
+		require.Equal(t, exp, line)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("line %q is not printed", exp)
+	}
+}
+
+func (wc *watchedCmd) requireCode(t *testing.T, exp int) {
+	t.Helper()
+	select {
+	case code := <-wc.code:
+		require.Equal(t, exp, code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher is not finished")
+	}
+}
+
+const watchScript = `echo "start $FOO"; trap 'echo "stop $FOO"; exit 0' TERM; while :; do sleep 0.05; done`
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "FOO"), []byte("1"), 0o644))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &Watcher{Dirs: []string{dir}, Debounce: 50 * time.Millisecond, StopTimeout: time.Second}
+	wc := startWatcher(ctx, t, w, watchScript)
+	wc.requireLine(t, "start 1")
+
+	// ignored file does not change environment
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "A=B"), []byte("2"), 0o644))
+	time.Sleep(200 * time.Millisecond)
+
+	// several changes are debounced to one restart
+	for _, value := range []string{"2", "3"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "FOO"), []byte(value), 0o644))
+	}
+	wc.requireLine(t, "stop 1")
+	wc.requireLine(t, "start 3")
+
+	// subdirectories are ignored without namespaces
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "FOO"), []byte("4"), 0o644))
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "FOO"), []byte("5"), 0o644))
+	time.Sleep(200 * time.Millisecond)
+	require.Empty(t, wc.lines)
+
+	cancel()
+	wc.requireLine(t, "stop 3")
+	wc.requireCode(t, 0)
+}
+
+func TestWatcherNamespaces(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	envFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte("FOO=1"), 0o644))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &Watcher{
+		Dirs:        []string{envFile, dir},
+		ReadOptions: []ReadOption{WithNamespaces()},
+		Debounce:    50 * time.Millisecond,
+	}
+	script := `echo "start $FOO $SUB_FOO $NEW_FOO"; trap 'exit 0' TERM; while :; do sleep 0.05; done`
+	wc := startWatcher(ctx, t, w, script)
+	wc.requireLine(t, "start 1  ")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "FOO"), []byte("2"), 0o644))
+	wc.requireLine(t, "start 1 2 ")
+
+	// new subdirectories are watched too
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "new"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new", "FOO"), []byte("a"), 0o644))
+	wc.requireLine(t, "start 1 2 a")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new", "FOO"), []byte("b"), 0o644))
+	wc.requireLine(t, "start 1 2 b")
+
+	// file is replaced by rename as editors do
+	require.NoError(t, os.WriteFile(envFile+".tmp", []byte("FOO=3"), 0o644))
+	require.NoError(t, os.Rename(envFile+".tmp", envFile))
+	wc.requireLine(t, "start 3 2 b")
+
+	// invalid file is reported and command keeps running
+	require.NoError(t, os.WriteFile(envFile, []byte("FOO='3"), 0o644))
+	time.Sleep(200 * time.Millisecond)
+	require.Empty(t, wc.lines)
+
+	cancel()
+	wc.requireCode(t, 0)
+}
+
+func TestInotifyNames(t *testing.T) {
+	dir := t.TempDir()
+	n, err := newNotifier()
+	require.NoError(t, err)
+	defer n.close()
+	require.NoError(t, n.add(dir, "app.env"))
+
+	changes := make(chan error, 1)
+	go func() {
+		changes <- n.wait()
+	}()
+
+	// other files in directory are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("1"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	select {
+	case <-changes:
+		t.Fatal("change of other file is reported")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.env"), []byte("FOO=1"), 0o644))
+	select {
+	case err := <-changes:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change of watched file is not reported")
+	}
+
+	// the whole directory is watched after adding it without names
+	require.NoError(t, n.add(dir))
+	go func() {
+		changes <- n.wait()
+	}()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("2"), 0o644))
+	select {
+	case err := <-changes:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change in directory is not reported")
+	}
+}
+
+func TestWatcherStop(t *testing.T) {
+	t.Run("command exits", func(t *testing.T) {
+		w := &Watcher{Dirs: []string{t.TempDir()}}
+		wc := startWatcher(context.Background(), t, w, "echo done; exit 3")
+		wc.requireLine(t, "done")
+		wc.requireCode(t, 3)
+	})
+
+	t.Run("killed after timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &Watcher{Dirs: []string{t.TempDir()}, StopTimeout: 100 * time.Millisecond}
+		wc := startWatcher(ctx, t, w, `trap '' TERM; echo started; while :; do sleep 0.05; done`)
+		wc.requireLine(t, "started")
+		cancel()
+		wc.requireCode(t, 128+int(syscall.SIGKILL))
+	})
+
+	t.Run("custom stop signal", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &Watcher{Dirs: []string{t.TempDir()}, StopSignal: syscall.SIGUSR1}
+		wc := startWatcher(ctx, t, w, `trap 'exit 7' USR1; echo started; while :; do sleep 0.05; done`)
+		wc.requireLine(t, "started")
+		cancel()
+		wc.requireCode(t, 7)
+	})
+
+	t.Run("invalid directory", func(t *testing.T) {
+		w := &Watcher{Dirs: []string{"hzhz"}}
+		wc := startWatcher(context.Background(), t, w, "true")
+		wc.requireCode(t, 1)
+	})
+}
+
+func TestParseSignal(t *testing.T) {
+	signals := map[string]os.Signal{"TERM": syscall.SIGTERM, "sigint": syscall.SIGINT, "HUP": syscall.SIGHUP}
+	for name, exp := range signals {
+		sig, err := ParseSignal(name)
+		require.NoError(t, err)
+		require.Equal(t, exp, sig)
+	}
+	_, err := ParseSignal("HZHZ")
+	require.ErrorIs(t, err, ErrUnknownSignal)
+}
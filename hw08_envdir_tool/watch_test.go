@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier records watched directories with names of files.
+type recordingNotifier struct {
+	watches map[string][]string
+}
+
+func (n *recordingNotifier) add(dir string, names ...string) error {
+	n.watches[dir] = append(n.watches[dir], names...)
+	return nil
+}
+
+func (n *recordingNotifier) wait() error {
+	return io.EOF
+}
+
+func (n *recordingNotifier) close() error {
+	return nil
+}
+
+func TestWatcherAddWatches(t *testing.T) {
+	project := t.TempDir()
+	envFile := filepath.Join(project, ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte("FOO=1"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(project, ".git", "objects"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "node_modules", "pkg"), 0o755))
+	envDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(envDir, "db", "replica"), 0o755))
+
+	t.Run("without namespaces", func(t *testing.T) {
+		n := &recordingNotifier{watches: map[string][]string{}}
+		w := &Watcher{Dirs: []string{envFile, envDir}}
+		w.addWatches(n, io.Discard)
+		require.Equal(t, map[string][]string{
+			project: {".env"}, // only the file is interesting in its directory
+			envDir:  nil,
+		}, n.watches)
+	})
+
+	t.Run("with namespaces", func(t *testing.T) {
+		n := &recordingNotifier{watches: map[string][]string{}}
+		w := &Watcher{Dirs: []string{envFile, envDir}, ReadOptions: []ReadOption{WithNamespaces()}}
+		w.addWatches(n, io.Discard)
+		require.Equal(t, map[string][]string{
+			project:                                {".env"},
+			envDir:                                 nil,
+			filepath.Join(envDir, "db"):            nil,
+			filepath.Join(envDir, "db", "replica"): nil,
+		}, n.watches)
+	})
+}
@@ -0,0 +1,288 @@
+package hw09structvalidator
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	EmailValidation    struct{}
+	UUIDValidation     struct{}
+	URLValidation      struct{}
+	IPValidation       struct{}
+	NonZeroValidation  struct{}
+	OneOfValidation    struct{}
+	DatetimeValidation struct{}
+)
+
+var schemeRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*$`)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validate checks that string is a plain email address without display name.
+func (validator EmailValidation) Validate(v interface{}, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	return validateStrings(v, func(s string) error {
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return NewValidationError(fmt.Errorf("%w: '%s'", ErrValidationEmail, s))
+		}
+		return nil
+	})
+}
+
+// Validate checks that string is UUID in canonical form.
+func (validator UUIDValidation) Validate(v interface{}, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	return validateStrings(v, func(s string) error {
+		if !uuidRegexp.MatchString(s) {
+			return NewValidationError(fmt.Errorf("%w: '%s'", ErrValidationUUID, s))
+		}
+		return nil
+	})
+}
+
+// Validate checks that string is absolute URL with host, args are allowed schemes, e.g. url:http,https.
+func (validator URLValidation) Validate(v interface{}, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	return validateStrings(v, func(s string) error {
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return NewValidationError(fmt.Errorf("%w: '%s'", ErrValidationURL, s))
+		}
+		if len(args) == 0 {
+			return nil
+		}
+		for _, scheme := range args {
+			if strings.EqualFold(u.Scheme, scheme) {
+				return nil
+			}
+		}
+		return NewValidationError(
+			fmt.Errorf("%w: scheme of '%s' not in %s", ErrValidationURL, s, strings.Join(args, ", ")),
+		)
+	})
+}
+
+// Validate checks that string is IP address, optional arg v4 or v6 restricts version.
+func (validator IPValidation) Validate(v interface{}, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	version := ""
+	if len(args) == 1 {
+		version = args[0]
+	}
+	return validateStrings(v, func(s string) error {
+		ip := net.ParseIP(s)
+		isV4 := ip != nil && ip.To4() != nil
+		if ip == nil || (version == "v4" && !isV4) || (version == "v6" && isV4) {
+			return NewValidationError(fmt.Errorf("%w: '%s'", ErrValidationIP, s))
+		}
+		return nil
+	})
+}
+
+// Validate checks that value is not zero value of its type, slices and maps must be not empty.
+func (validator NonZeroValidation) Validate(v interface{}, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	refValue := reflect.ValueOf(v)
+	//nolint: exhaustive
+	switch refValue.Kind() {
+	case reflect.Invalid:
+		return NewValidationError(ErrValidationNonZero)
+	case reflect.Slice, reflect.Map:
+		if refValue.Len() == 0 {
+			return NewValidationError(ErrValidationNonZero)
+		}
+	default:
+		if refValue.IsZero() {
+			return NewValidationError(ErrValidationNonZero)
+		}
+	}
+	return nil
+}
+
+// ValidateNil reports that nil pointer is zero.
+func (validator NonZeroValidation) ValidateNil(args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	return NewValidationError(ErrValidationNonZero)
+}
+
+// oneOfValidation is OneOfValidation with args parsed as signed and unsigned integers.
+type oneOfValidation struct {
+	ints  []int64
+	uints []uint64
+	args  []string
+}
+
+// Validate checks that signed or unsigned integer is one of args.
+func (validator OneOfValidation) Validate(v interface{}, args ...string) error {
+	return validateCompiled(validator, v, args)
+}
+
+// Compile parses args as integers.
+func (validator OneOfValidation) Compile(args ...string) (Validator, error) {
+	if len(args) < 1 {
+		return nil, NewParseValidatorError(ErrExpectedAtLeastOneParameter)
+	}
+	compiled := oneOfValidation{args: args}
+	for _, arg := range args {
+		n, errInt := strconv.ParseInt(arg, 10, 64)
+		u, errUint := strconv.ParseUint(arg, 10, 64)
+		if errInt != nil && errUint != nil {
+			return nil, NewParseValidatorError(fmt.Errorf("%w: %s is not integer", ErrInvalidParameter, arg))
+		}
+		if errInt == nil {
+			compiled.ints = append(compiled.ints, n)
+		}
+		if errUint == nil {
+			compiled.uints = append(compiled.uints, u)
+		}
+	}
+	return compiled, nil
+}
+
+func (validator oneOfValidation) Validate(v interface{}, _ ...string) error {
+	refValue := reflect.ValueOf(v)
+	//nolint: exhaustive
+	switch refValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for _, n := range validator.ints {
+			if n == refValue.Int() {
+				return nil
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		for _, n := range validator.uints {
+			if n == refValue.Uint() {
+				return nil
+			}
+		}
+	case reflect.Slice:
+		if err := validateItems(refValue, func(item interface{}) error {
+			return validator.Validate(item)
+		}); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return NewParseValidatorError(ErrInappropriateType)
+	}
+	return NewValidationError(
+		fmt.Errorf("%w: %v not in (%s)", ErrValidationOneOf, refValue.Interface(), strings.Join(validator.args, ", ")),
+	)
+}
+
+// Validate checks that string is time in layout of time.Parse, e.g. datetime:2006-01-02.
+// Layout can contain commas, e.g. datetime:Mon, 02 Jan 2006.
+func (validator DatetimeValidation) Validate(v interface{}, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	layout := strings.Join(args, ",")
+	return validateStrings(v, func(s string) error {
+		if _, err := time.Parse(layout, s); err != nil {
+			return NewValidationError(fmt.Errorf("%w: '%s' does not match layout '%s'", ErrValidationDatetime, s, layout))
+		}
+		return nil
+	})
+}
+
+// CheckArgs checks that email has no args.
+func (validator EmailValidation) CheckArgs(args ...string) error {
+	return checkNoArgs(args)
+}
+
+// CheckArgs checks that uuid has no args.
+func (validator UUIDValidation) CheckArgs(args ...string) error {
+	return checkNoArgs(args)
+}
+
+// CheckArgs checks that nonzero has no args.
+func (validator NonZeroValidation) CheckArgs(args ...string) error {
+	return checkNoArgs(args)
+}
+
+func checkNoArgs(args []string) error {
+	if len(args) != 0 {
+		return NewParseValidatorError(ErrUnexpectedParameter)
+	}
+	return nil
+}
+
+// CheckArgs checks that args are URL schemes.
+func (validator URLValidation) CheckArgs(args ...string) error {
+	for _, scheme := range args {
+		if !schemeRegexp.MatchString(scheme) {
+			return NewParseValidatorError(fmt.Errorf("%w: invalid scheme '%s'", ErrInvalidParameter, scheme))
+		}
+	}
+	return nil
+}
+
+// CheckArgs checks that optional version is v4 or v6.
+func (validator IPValidation) CheckArgs(args ...string) error {
+	if len(args) > 1 {
+		return NewParseValidatorError(ErrExpectedOneParameter)
+	}
+	if len(args) == 1 && args[0] != "v4" && args[0] != "v6" {
+		return NewParseValidatorError(fmt.Errorf("%w: %s, expected v4 or v6", ErrInvalidParameter, args[0]))
+	}
+	return nil
+}
+
+// CheckArgs checks that layout is given.
+func (validator DatetimeValidation) CheckArgs(args ...string) error {
+	if len(args) == 0 {
+		return NewParseValidatorError(ErrExpectedOneParameter)
+	}
+	return nil
+}
+
+// validateStrings calls check for string v or for each string of slice v.
+func validateStrings(v interface{}, check func(s string) error) error {
+	refValue := reflect.ValueOf(v)
+	//nolint: exhaustive
+	switch refValue.Kind() {
+	case reflect.String:
+		return check(refValue.String())
+	case reflect.Slice:
+		if err := validateItems(refValue, func(item interface{}) error {
+			return validateStrings(item, check)
+		}); err != nil {
+			return err
+		}
+	default:
+		return NewParseValidatorError(ErrInappropriateType)
+	}
+	return nil
+}
+
+var (
+	_ ArgsChecker  = (*EmailValidation)(nil)
+	_ ArgsChecker  = (*UUIDValidation)(nil)
+	_ ArgsChecker  = (*URLValidation)(nil)
+	_ ArgsChecker  = (*IPValidation)(nil)
+	_ ArgsChecker  = (*NonZeroValidation)(nil)
+	_ NilValidator = (*NonZeroValidation)(nil)
+	_ Compiler     = (*OneOfValidation)(nil)
+	_ ArgsChecker  = (*DatetimeValidation)(nil)
+)
@@ -0,0 +1,86 @@
+package hw09structvalidator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinValidators(t *testing.T) {
+	tests := []struct {
+		validator Validator
+		v         interface{}
+		args      []string
+		err       error
+	}{
+		{EmailValidation{}, "test@test.com", nil, nil},
+		{EmailValidation{}, "test.name+tag@mail.example.com", nil, nil},
+		{EmailValidation{}, []string{"a@b.c", "d@e.f"}, nil, nil},
+		{EmailValidation{}, "test.com", nil, ErrValidationEmail},
+		{EmailValidation{}, "Test <test@test.com>", nil, ErrValidationEmail},
+		{EmailValidation{}, []string{"a@b.c", "d"}, nil, ErrValidationEmail},
+		{EmailValidation{}, "test@test.com", []string{"1"}, ErrUnexpectedParameter},
+		{EmailValidation{}, 1, nil, ErrInappropriateType},
+
+		{UUIDValidation{}, "2030edfd-1610-44d1-a7fd-c08efab88a3e", nil, nil},
+		{UUIDValidation{}, "2030EDFD-1610-44D1-A7FD-C08EFAB88A3E", nil, nil},
+		{UUIDValidation{}, "2030edfd161044d1a7fdc08efab88a3e", nil, ErrValidationUUID},
+		{UUIDValidation{}, "2030edfd-1610-44d1-a7fd-c08efab88a3z", nil, ErrValidationUUID},
+
+		{URLValidation{}, "https://example.com/path?q=1", nil, nil},
+		{URLValidation{}, "ftp://example.com", nil, nil},
+		{URLValidation{}, "HTTPS://example.com", []string{"http", "https"}, nil},
+		{URLValidation{}, "ftp://example.com", []string{"http", "https"}, ErrValidationURL},
+		{URLValidation{}, "/path", nil, ErrValidationURL},
+		{URLValidation{}, "example.com", nil, ErrValidationURL},
+		{URLValidation{}, "http://exa mple.com", nil, ErrValidationURL},
+
+		{IPValidation{}, "127.0.0.1", nil, nil},
+		{IPValidation{}, "::1", nil, nil},
+		{IPValidation{}, "127.0.0.1", []string{"v4"}, nil},
+		{IPValidation{}, "::1", []string{"v6"}, nil},
+		{IPValidation{}, "::1", []string{"v4"}, ErrValidationIP},
+		{IPValidation{}, "127.0.0.1", []string{"v6"}, ErrValidationIP},
+		{IPValidation{}, "256.0.0.1", nil, ErrValidationIP},
+		{IPValidation{}, "127.0.0.1", []string{"v5"}, ErrInvalidParameter},
+		{IPValidation{}, "127.0.0.1", []string{"v4", "v6"}, ErrExpectedOneParameter},
+
+		{NonZeroValidation{}, 1, nil, nil},
+		{NonZeroValidation{}, "a", nil, nil},
+		{NonZeroValidation{}, []int{0}, nil, nil},
+		{NonZeroValidation{}, struct{ A int }{1}, nil, nil},
+		{NonZeroValidation{}, 0, nil, ErrValidationNonZero},
+		{NonZeroValidation{}, "", nil, ErrValidationNonZero},
+		{NonZeroValidation{}, []int{}, nil, ErrValidationNonZero},
+		{NonZeroValidation{}, map[string]int{}, nil, ErrValidationNonZero},
+		{NonZeroValidation{}, struct{ A int }{}, nil, ErrValidationNonZero},
+		{NonZeroValidation{}, nil, nil, ErrValidationNonZero},
+		{NonZeroValidation{}, 1, []string{"1"}, ErrUnexpectedParameter},
+
+		{OneOfValidation{}, 2, []string{"1", "2"}, nil},
+		{OneOfValidation{}, int8(-2), []string{"-2", "2"}, nil},
+		{OneOfValidation{}, uint64(18446744073709551615), []string{"18446744073709551615"}, nil},
+		{OneOfValidation{}, []uint{1, 2}, []string{"1", "2"}, nil},
+		{OneOfValidation{}, 3, []string{"1", "2"}, ErrValidationOneOf},
+		{OneOfValidation{}, uint(1), []string{"-1"}, ErrValidationOneOf},
+		{OneOfValidation{}, []int{1, 3}, []string{"1", "2"}, ErrValidationOneOf},
+		{OneOfValidation{}, 1, []string{"1", "a"}, ErrInvalidParameter},
+		{OneOfValidation{}, 1, nil, ErrExpectedAtLeastOneParameter},
+		{OneOfValidation{}, "1", []string{"1"}, ErrInappropriateType},
+
+		{DatetimeValidation{}, "2022-05-08", []string{"2006-01-02"}, nil},
+		{DatetimeValidation{}, "12:30:00", []string{"15:04:05"}, nil},
+		{DatetimeValidation{}, "Sun, 08 May 2022", []string{"Mon", " 02 Jan 2006"}, nil},
+		{DatetimeValidation{}, "2022-13-08", []string{"2006-01-02"}, ErrValidationDatetime},
+		{DatetimeValidation{}, []string{"2022-05-08", "08.05.2022"}, []string{"2006-01-02"}, ErrValidationDatetime},
+		{DatetimeValidation{}, "2022-05-08", nil, ErrExpectedOneParameter},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%T: %v args: %v", tc.validator, tc.v, tc.args), func(t *testing.T) {
+			err := tc.validator.Validate(tc.v, tc.args...)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
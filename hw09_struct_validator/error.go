@@ -15,6 +15,9 @@ var (
 	ErrExpectedAtLeastOneParameter = errors.New("expected at least one parameter")
 	ErrInvalidType                 = errors.New("invalid type of variable, expected struct")
 	ErrInappropriateType           = errors.New("validator is not appropriate for this type")
+	ErrUnexpectedParameter         = errors.New("validator has no parameters")
+	ErrFieldDoesNotExist           = errors.New("field does not exist")
+	ErrFieldContextRequired        = errors.New("validator can be used in struct tag only")
 )
 
 var (
@@ -24,6 +27,20 @@ var (
 	ErrValidationMax         = errors.New("max validator error")
 	ErrValidationStringRange = errors.New("invalid string range")
 	ErrValidationRegexp      = errors.New("string does not match regexp")
+	ErrValidationEmail       = errors.New("invalid email")
+	ErrValidationUUID        = errors.New("invalid uuid")
+	ErrValidationURL         = errors.New("invalid url")
+	ErrValidationIP          = errors.New("invalid ip address")
+	ErrValidationNonZero     = errors.New("value is zero")
+	ErrValidationOneOf       = errors.New("value is not one of allowed")
+	ErrValidationDatetime    = errors.New("invalid datetime")
+	ErrValidationEqField     = errors.New("value is not equal to field")
+	ErrValidationNeField     = errors.New("value is equal to field")
+	ErrValidationGtField     = errors.New("value is not greater than field")
+	ErrValidationGteField    = errors.New("value is less than field")
+	ErrValidationLtField     = errors.New("value is not less than field")
+	ErrValidationLteField    = errors.New("value is greater than field")
+	ErrValidationRequiredIf  = errors.New("value is required")
 )
 
 type ParseValidatorError struct {
@@ -44,9 +61,15 @@ var (
 	_ xerrors.Wrapper = (*ParseValidatorError)(nil)
 )
 
+// ValidationError is an error of field value, Rule and Params are name and args of validator.
+// Value is the actual value of field or of failed item of slice field,
+// it is RedactedValue if field has tag redact:"true".
 type ValidationError struct {
-	Field string
-	Err   error
+	Field  string
+	Rule   string
+	Params []string
+	Value  interface{}
+	Err    error
 }
 
 func (v ValidationError) Unwrap() error {
@@ -0,0 +1,260 @@
+package hw09structvalidator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldContext describes validated field of struct.
+type FieldContext struct {
+	// Parent is a struct containing field.
+	Parent reflect.Value
+	Field  reflect.StructField
+	// Value is a value of field, pointers are not dereferenced.
+	Value reflect.Value
+	// Path is a name of field in errors, e.g. Items[2].Name.
+	Path string
+}
+
+// Sibling returns value of other field of parent struct, name can be a path to nested field, e.g. Address.Zip.
+// Pointers are dereferenced, ok is false if one of them is nil.
+func (c FieldContext) Sibling(name string) (value reflect.Value, ok bool, err error) {
+	value = c.Parent
+	for _, part := range strings.Split(name, ".") {
+		if value, ok = indirect(value); !ok {
+			return value, false, nil
+		}
+		if value.Kind() != reflect.Struct {
+			return value, false, NewParseValidatorError(fmt.Errorf("%w: %s", ErrFieldDoesNotExist, name))
+		}
+		field, found := value.Type().FieldByName(part)
+		if !found || field.PkgPath != "" {
+			return value, false, NewParseValidatorError(fmt.Errorf("%w: %s", ErrFieldDoesNotExist, name))
+		}
+		value = value.FieldByIndex(field.Index)
+	}
+	value, ok = indirect(value)
+	return value, ok, nil
+}
+
+// indirect dereferences pointers, ok is false for nil pointer.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// Comparison operators of CompareFieldValidation.
+const (
+	OpEq  = "eq"
+	OpNe  = "ne"
+	OpGt  = "gt"
+	OpGte = "gte"
+	OpLt  = "lt"
+	OpLte = "lte"
+)
+
+type (
+	// CompareFieldValidation compares value with other field, e.g. gtfield:StartedAt.
+	// Numbers, strings and time.Time are ordered, other values can be checked for equality only.
+	// Nil pointers are not compared.
+	CompareFieldValidation struct {
+		Op string
+	}
+	// RequiredIfValidation requires nonzero value if other fields have given values,
+	// e.g. required_if:Type,admin or required_if:Type,admin,Active,true.
+	RequiredIfValidation struct{}
+)
+
+var compareErrors = map[string]error{
+	OpEq:  ErrValidationEqField,
+	OpNe:  ErrValidationNeField,
+	OpGt:  ErrValidationGtField,
+	OpGte: ErrValidationGteField,
+	OpLt:  ErrValidationLtField,
+	OpLte: ErrValidationLteField,
+}
+
+func (validator CompareFieldValidation) Validate(interface{}, ...string) error {
+	return NewParseValidatorError(ErrFieldContextRequired)
+}
+
+// CheckArgs checks that the only arg is a name of field and operator is known.
+func (validator CompareFieldValidation) CheckArgs(args ...string) error {
+	if len(args) != 1 {
+		return NewParseValidatorError(ErrExpectedOneParameter)
+	}
+	if _, ok := compareErrors[validator.Op]; !ok {
+		return NewParseValidatorError(fmt.Errorf("%w: unknown operator %s", ErrInvalidParameter, validator.Op))
+	}
+	return nil
+}
+
+// Fields returns name of compared field.
+func (validator CompareFieldValidation) Fields(args ...string) []string {
+	return args
+}
+
+func (validator CompareFieldValidation) ValidateContext(ctx FieldContext, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	errValidation := compareErrors[validator.Op]
+	other, ok, err := ctx.Sibling(args[0])
+	if err != nil || !ok {
+		return err
+	}
+	value, ok := indirect(ctx.Value)
+	if !ok {
+		return nil
+	}
+	cmp, ordered := compareValues(value, other)
+	if !ordered {
+		if (validator.Op != OpEq && validator.Op != OpNe) || value.Type() != other.Type() {
+			return NewParseValidatorError(
+				fmt.Errorf("%w: can not compare %s and %s", ErrInappropriateType, value.Type(), other.Type()),
+			)
+		}
+		cmp = 1
+		if reflect.DeepEqual(value.Interface(), other.Interface()) {
+			cmp = 0
+		}
+	}
+	var valid bool
+	switch validator.Op {
+	case OpEq:
+		valid = cmp == 0
+	case OpNe:
+		valid = cmp != 0
+	case OpGt:
+		valid = cmp > 0
+	case OpGte:
+		valid = cmp >= 0
+	case OpLt:
+		valid = cmp < 0
+	case OpLte:
+		valid = cmp <= 0
+	}
+	if !valid {
+		return NewValidationError(fmt.Errorf("%w %s: %v and %v", errValidation, args[0], value, other))
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareValues returns -1, 0 or 1 if a is less, equal or greater than b, ok is false if values are not ordered.
+func compareValues(a, b reflect.Value) (cmp int, ok bool) {
+	if a.Type() == timeType && b.Type() == timeType {
+		at, bt := a.Interface().(time.Time), b.Interface().(time.Time)
+		switch {
+		case at.Before(bt):
+			return -1, true
+		case at.After(bt):
+			return 1, true
+		}
+		return 0, true
+	}
+	switch {
+	case isInt(a.Kind()) && isInt(b.Kind()):
+		return compare(a.Int() < b.Int(), a.Int() > b.Int()), true
+	case isUint(a.Kind()) && isUint(b.Kind()):
+		return compare(a.Uint() < b.Uint(), a.Uint() > b.Uint()), true
+	case isInt(a.Kind()) && isUint(b.Kind()):
+		if a.Int() < 0 {
+			return -1, true
+		}
+		return compare(uint64(a.Int()) < b.Uint(), uint64(a.Int()) > b.Uint()), true
+	case isUint(a.Kind()) && isInt(b.Kind()):
+		cmp, _ := compareValues(b, a)
+		return -cmp, true
+	case isFloat(a.Kind()) && isFloat(b.Kind()):
+		return compare(a.Float() < b.Float(), a.Float() > b.Float()), true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	}
+	return 0, false
+}
+
+func compare(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+// CheckArgs checks that args are pairs of field and value.
+func (validator RequiredIfValidation) CheckArgs(args ...string) error {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return NewParseValidatorError(fmt.Errorf("%w: expected pairs of field and value", ErrInvalidParameter))
+	}
+	return nil
+}
+
+// Fields returns names of fields from pairs of field and value.
+func (validator RequiredIfValidation) Fields(args ...string) []string {
+	fields := make([]string, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		fields = append(fields, args[i])
+	}
+	return fields
+}
+
+func (validator RequiredIfValidation) Validate(interface{}, ...string) error {
+	return NewParseValidatorError(ErrFieldContextRequired)
+}
+
+// ValidateContext checks that all pairs of field and value in args match and then requires nonzero value.
+// Value of field is compared with its string representation.
+func (validator RequiredIfValidation) ValidateContext(ctx FieldContext, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
+	for i := 0; i < len(args); i += 2 {
+		other, ok, err := ctx.Sibling(args[i])
+		if err != nil {
+			return err
+		}
+		if !ok || fmt.Sprint(other.Interface()) != args[i+1] {
+			return nil
+		}
+	}
+	value, ok := indirect(ctx.Value)
+	isEmpty := (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0
+	if !ok || value.IsZero() || isEmpty {
+		return NewValidationError(
+			fmt.Errorf("%w if %s", ErrValidationRequiredIf, strings.Join(args, ", ")),
+		)
+	}
+	return nil
+}
+
+var (
+	_ ContextValidator = (*CompareFieldValidation)(nil)
+	_ ArgsChecker      = (*CompareFieldValidation)(nil)
+	_ FieldReferrer    = (*CompareFieldValidation)(nil)
+	_ ContextValidator = (*RequiredIfValidation)(nil)
+	_ ArgsChecker      = (*RequiredIfValidation)(nil)
+	_ FieldReferrer    = (*RequiredIfValidation)(nil)
+)
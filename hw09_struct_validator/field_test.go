@@ -0,0 +1,183 @@
+package hw09structvalidator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	Period struct {
+		StartedAt  time.Time
+		FinishedAt time.Time `validate:"gtfield:StartedAt"`
+	}
+
+	Contact struct {
+		Type     string
+		Active   bool
+		Phone    string   `validate:"required_if:Type,phone"`
+		Email    *string  `validate:"required_if:Type,email,Active,true"`
+		Password string   `validate:"len:3"`
+		Confirm  string   `validate:"eqfield:Password"`
+		Old      string   `validate:"nefield:Password"`
+		Min      int      `validate:"ltefield:Max"`
+		Max      int64    `validate:"gtefield:Limits.Low|ltfield:Limits.High"`
+		Limits   *Limits  `validate:"nested"`
+		Tags     []string `validate:"required_if:Type,tags"`
+	}
+
+	Limits struct {
+		Low  int
+		High uint `validate:"gtfield:Low"`
+	}
+)
+
+func TestCrossFieldValidation(t *testing.T) {
+	now := time.Now()
+	email := "a@b.c"
+	valid := Contact{
+		Type: "phone", Phone: "123", Email: &email, Password: "abc", Confirm: "abc", Old: "xyz",
+		Min: 1, Max: 2, Limits: &Limits{Low: 1, High: 5},
+	}
+
+	tests := []struct {
+		in     interface{}
+		fields []string
+		errs   []error
+	}{
+		{Period{StartedAt: now, FinishedAt: now.Add(time.Second)}, nil, nil},
+		{Period{StartedAt: now, FinishedAt: now}, []string{"FinishedAt"}, []error{ErrValidationGtField}},
+		{valid, nil, nil},
+		{
+			func() Contact {
+				c := valid
+				c.Phone, c.Confirm, c.Old, c.Min = "", "abd", "abc", 3
+				return c
+			}(),
+			[]string{"Phone", "Confirm", "Old", "Min"},
+			[]error{ErrValidationRequiredIf, ErrValidationEqField, ErrValidationNeField, ErrValidationLteField},
+		},
+		{
+			func() Contact {
+				c := valid
+				c.Type, c.Phone, c.Email, c.Active = "email", "", nil, false
+				return c
+			}(),
+			nil, nil,
+		},
+		{
+			func() Contact {
+				c := valid
+				c.Type, c.Email, c.Active = "email", nil, true
+				return c
+			}(),
+			[]string{"Email"}, []error{ErrValidationRequiredIf},
+		},
+		{
+			func() Contact {
+				c := valid
+				c.Type = "tags"
+				return c
+			}(),
+			[]string{"Tags"}, []error{ErrValidationRequiredIf},
+		},
+		{
+			func() Contact {
+				c := valid
+				c.Min, c.Max = 0, 0
+				c.Limits = &Limits{Low: 1, High: 1}
+				return c
+			}(),
+			[]string{"Max", "Limits.High"},
+			[]error{ErrValidationGteField, ErrValidationGtField},
+		},
+		{
+			func() Contact {
+				c := valid
+				c.Max = 5
+				return c
+			}(),
+			[]string{"Max"}, []error{ErrValidationLtField},
+		},
+		{
+			// nil pointers are not compared
+			func() Contact {
+				c := valid
+				c.Limits = nil
+				return c
+			}(),
+			nil, nil,
+		},
+	}
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			err := Validate(tc.in)
+			if len(tc.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			var errs ValidationErrors
+			require.ErrorAs(t, err, &errs)
+			require.Len(t, errs, len(tc.errs))
+			for i := range tc.errs {
+				require.Equal(t, tc.fields[i], errs[i].Field)
+				require.ErrorIs(t, errs[i], tc.errs[i])
+			}
+		})
+	}
+}
+
+func TestCrossFieldError(t *testing.T) {
+	tests := []struct {
+		data interface{}
+		err  error
+		tag  string
+	}{
+		{struct {
+			A int `validate:"gtfield:B"`
+		}{}, ErrFieldDoesNotExist, "gtfield:B"},
+		{struct {
+			A int `validate:"gtfield:b"`
+			b int
+		}{}, ErrFieldDoesNotExist, "gtfield:b"},
+		{struct {
+			A int `validate:"gtfield:B.C"`
+			B int
+		}{}, ErrFieldDoesNotExist, "gtfield:B.C"},
+		{struct {
+			A int `validate:"gtfield"`
+		}{}, ErrExpectedOneParameter, "gtfield"},
+		{struct {
+			A int `validate:"gtfield:B"`
+			B string
+		}{}, ErrInappropriateType, "gtfield:B"},
+		{struct {
+			A []int `validate:"gtfield:B"`
+			B []int
+		}{}, ErrInappropriateType, "gtfield:B"},
+		{struct {
+			A int `validate:"required_if:B"`
+			B string
+		}{}, ErrInvalidParameter, "required_if:B"},
+	}
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("test case: #%d", i), func(t *testing.T) {
+			err := Validate(tc.data)
+			actErr, ok := err.(ParseValidatorError) // nolint:errorlint
+			require.True(t, ok)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.tag, actErr.Tag)
+		})
+	}
+
+	require.NoError(t, Validate(struct {
+		A []int `validate:"eqfield:B"`
+		B []int
+	}{A: []int{1}, B: []int{1}}))
+	require.ErrorIs(t, CompareFieldValidation{Op: OpEq}.Validate(1, "A"), ErrFieldContextRequired)
+	require.ErrorIs(t, RequiredIfValidation{}.Validate(1, "A", "1"), ErrFieldContextRequired)
+}
@@ -0,0 +1,133 @@
+package hw09structvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Translator returns human-readable message of validation error.
+type Translator interface {
+	Translate(e ValidationError) string
+}
+
+// Catalog is a Translator with message templates for errors of validation, e.g. ErrValidationMin.
+// Templates can contain placeholders {field}, {params} and {value}.
+// Errors without template are translated by their Error.
+type Catalog map[error]string
+
+// Translate finds template by e.Err or errors wrapped by it and fills placeholders.
+func (c Catalog) Translate(e ValidationError) string {
+	for err := e.Err; err != nil; err = errors.Unwrap(err) {
+		for key, template := range c {
+			if !isError(err, key) {
+				continue
+			}
+			return strings.NewReplacer(
+				"{field}", e.Field,
+				"{params}", strings.Join(e.Params, ", "),
+				"{value}", fmt.Sprint(e.Value),
+			).Replace(template)
+		}
+	}
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+// isError reports if err is target without unwrapping like errors.Is does.
+// Keys of catalog are hashable, so comparison does not panic even for err with non-comparable fields.
+func isError(err, target error) bool {
+	if err == target {
+		return true
+	}
+	if x, ok := err.(interface{ Is(error) bool }); ok { // nolint:errorlint
+		return x.Is(target)
+	}
+	return false
+}
+
+// EnglishCatalog contains messages of built-in validators in English.
+var EnglishCatalog = Catalog{
+	ErrValidationIntRange:    "{field} must be one of {params}",
+	ErrValidationStringLen:   "{field} must be {params} characters long",
+	ErrValidationMin:         "{field} must be at least {params}",
+	ErrValidationMax:         "{field} must be at most {params}",
+	ErrValidationStringRange: "{field} must be one of {params}",
+	ErrValidationRegexp:      "{field} must match {params}",
+	ErrValidationEmail:       "{field} must be a valid email address",
+	ErrValidationUUID:        "{field} must be a valid UUID",
+	ErrValidationURL:         "{field} must be a valid URL",
+	ErrValidationIP:          "{field} must be a valid IP address",
+	ErrValidationNonZero:     "{field} is required",
+	ErrValidationOneOf:       "{field} must be one of {params}",
+	ErrValidationDatetime:    "{field} must be a date in format {params}",
+	ErrValidationEqField:     "{field} must be equal to {params}",
+	ErrValidationNeField:     "{field} must not be equal to {params}",
+	ErrValidationGtField:     "{field} must be greater than {params}",
+	ErrValidationGteField:    "{field} must be greater than or equal to {params}",
+	ErrValidationLtField:     "{field} must be less than {params}",
+	ErrValidationLteField:    "{field} must be less than or equal to {params}",
+	ErrValidationRequiredIf:  "{field} is required if {params}",
+}
+
+// RussianCatalog contains messages of built-in validators in Russian.
+var RussianCatalog = Catalog{
+	ErrValidationIntRange:    "{field} должно быть одним из: {params}",
+	ErrValidationStringLen:   "длина {field} должна быть {params} символов",
+	ErrValidationMin:         "{field} должно быть не меньше {params}",
+	ErrValidationMax:         "{field} должно быть не больше {params}",
+	ErrValidationStringRange: "{field} должно быть одним из: {params}",
+	ErrValidationRegexp:      "{field} должно соответствовать {params}",
+	ErrValidationEmail:       "{field} должно быть корректным email-адресом",
+	ErrValidationUUID:        "{field} должно быть корректным UUID",
+	ErrValidationURL:         "{field} должно быть корректным URL",
+	ErrValidationIP:          "{field} должно быть корректным IP-адресом",
+	ErrValidationNonZero:     "{field} обязательно",
+	ErrValidationOneOf:       "{field} должно быть одним из: {params}",
+	ErrValidationDatetime:    "{field} должно быть датой в формате {params}",
+	ErrValidationEqField:     "{field} должно быть равно {params}",
+	ErrValidationNeField:     "{field} не должно быть равно {params}",
+	ErrValidationGtField:     "{field} должно быть больше {params}",
+	ErrValidationGteField:    "{field} должно быть больше или равно {params}",
+	ErrValidationLtField:     "{field} должно быть меньше {params}",
+	ErrValidationLteField:    "{field} должно быть меньше или равно {params}",
+	ErrValidationRequiredIf:  "{field} обязательно, если {params}",
+}
+
+// DefaultTranslator translates messages of ValidationErrors.MarshalJSON.
+var DefaultTranslator Translator = EnglishCatalog
+
+// Message is machine-readable validation error.
+type Message struct {
+	Field   string   `json:"field"`
+	Rule    string   `json:"rule"`
+	Params  []string `json:"params"`
+	Message string   `json:"message"`
+}
+
+// Messages returns errors with messages translated by t.
+func (v ValidationErrors) Messages(t Translator) []Message {
+	msgs := make([]Message, 0, len(v))
+	for _, e := range v {
+		params := e.Params
+		if params == nil {
+			params = []string{}
+		}
+		msgs = append(msgs, Message{Field: e.Field, Rule: e.Rule, Params: params, Message: t.Translate(e)})
+	}
+	return msgs
+}
+
+// MarshalJSON encodes errors as a list of {field, rule, params, message} translated by DefaultTranslator.
+// Values of fields are not encoded.
+func (v ValidationErrors) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Messages(DefaultTranslator))
+}
+
+var (
+	_ Translator     = (*Catalog)(nil)
+	_ json.Marshaler = (*ValidationErrors)(nil)
+)
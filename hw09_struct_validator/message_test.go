@@ -0,0 +1,193 @@
+package hw09structvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Account struct {
+	Login    string `validate:"len:5"`
+	Age      int    `validate:"min:18|max:50"`
+	Password string `validate:"regexp:^\\w*\\d\\w*$" redact:"true"`
+	Admin    *bool  `validate:"nonzero"`
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	err := Validate(Account{Login: "bob", Age: 60, Password: "secret"})
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 4)
+
+	require.Equal(t, "len", errs[0].Rule)
+	require.Equal(t, []string{"5"}, errs[0].Params)
+	require.Equal(t, "bob", errs[0].Value)
+	require.Equal(t, "max", errs[1].Rule)
+	require.Equal(t, 60, errs[1].Value)
+	require.Equal(t, "regexp", errs[2].Rule)
+	require.Equal(t, RedactedValue, errs[2].Value)
+	require.Equal(t, "nonzero", errs[3].Rule)
+	require.Empty(t, errs[3].Params)
+}
+
+func TestValidationErrorItemValue(t *testing.T) {
+	type Contacts struct {
+		Phones []string `validate:"len:11"`
+		Codes  []int    `validate:"in:1,2"`
+		Tokens []string `validate:"len:3" redact:"true"`
+	}
+
+	err := Validate(Contacts{
+		Phones: []string{"79991234567", "123"},
+		Codes:  []int{1, 5},
+		Tokens: []string{"abcd"},
+	})
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 3)
+	require.Equal(t, "123", errs[0].Value)
+	require.Equal(t, 5, errs[1].Value)
+	require.Equal(t, RedactedValue, errs[2].Value)
+
+	msg := Catalog{ErrValidationStringLen: "{field}: {value}"}.Translate(errs[0])
+	require.Equal(t, "Phones: 123", msg)
+}
+
+func TestValidationErrorRedacted(t *testing.T) {
+	type Login struct {
+		Password string   `validate:"len:12" redact:"true"`
+		Token    string   `validate:"uuid" redact:"true"`
+		Keys     []string `validate:"len:3" redact:"true"`
+		Confirm  string   `validate:"eqfield:Password" redact:"true"`
+	}
+
+	err := Validate(Login{Password: "hunter2", Token: "hunter3", Keys: []string{"hunter4"}, Confirm: "hunter5"})
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 4)
+	require.NotContains(t, err.Error(), "hunter")
+	require.ErrorIs(t, errs[0], ErrValidationStringLen)
+	require.ErrorIs(t, errs[1], ErrValidationUUID)
+	require.ErrorIs(t, errs[2], ErrValidationStringLen)
+	require.ErrorIs(t, errs[3], ErrValidationEqField)
+	for _, e := range errs {
+		require.Equal(t, RedactedValue, e.Value)
+		require.NotContains(t, EnglishCatalog.Translate(e), "hunter")
+		require.NotContains(t, Catalog{}.Translate(e), "hunter")
+	}
+	require.Equal(t, "Password: invalid string len: ***", errs[0].Error())
+}
+
+func TestValidationErrorsJSON(t *testing.T) {
+	err := Validate(Account{Login: "alice", Age: 10, Password: "secret"})
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+
+	data, err := json.Marshal(errs)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"field": "Age", "rule": "min", "params": ["18"], "message": "Age must be at least 18"},
+		{"field": "Password", "rule": "regexp", "params": ["^\\w*\\d\\w*$"], "message": "Password must match ^\\w*\\d\\w*$"},
+		{"field": "Admin", "rule": "nonzero", "params": [], "message": "Admin is required"}
+	]`, string(data))
+	require.NotContains(t, string(data), "secret")
+}
+
+func TestCatalog(t *testing.T) {
+	errUnknown := errors.New("unknown error")
+	tests := []struct {
+		catalog Catalog
+		err     ValidationError
+		exp     string
+	}{
+		{
+			EnglishCatalog,
+			ValidationError{Field: "Age", Params: []string{"18"}, Err: ErrValidationMin},
+			"Age must be at least 18",
+		},
+		{
+			RussianCatalog,
+			ValidationError{Field: "Age", Params: []string{"18"}, Err: ErrValidationMin},
+			"Age должно быть не меньше 18",
+		},
+		{
+			RussianCatalog,
+			ValidationError{Field: "Role", Params: []string{"admin", "stuff"}, Err: ErrValidationStringRange},
+			"Role должно быть одним из: admin, stuff",
+		},
+		{
+			// wrapped errors are found
+			EnglishCatalog,
+			ValidationError{Field: "Flag", Err: NewValidationError(ErrValidationNonZero)},
+			"Flag is required",
+		},
+		{
+			Catalog{errUnknown: "{field} has invalid value {value}"},
+			ValidationError{Field: "N", Value: 3, Err: errUnknown},
+			"N has invalid value 3",
+		},
+		{EnglishCatalog, ValidationError{Field: "N", Err: errUnknown}, "unknown error"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.exp, func(t *testing.T) {
+			require.Equal(t, tc.exp, tc.catalog.Translate(tc.err))
+		})
+	}
+
+	// every built-in error has messages in all catalogs
+	require.Equal(t, len(EnglishCatalog), len(RussianCatalog))
+	for err := range EnglishCatalog {
+		require.Contains(t, RussianCatalog, err)
+	}
+}
+
+// wrappedErrors is comparable type holding non-comparable error.
+type wrappedErrors struct {
+	err error
+}
+
+func (w wrappedErrors) Error() string {
+	return w.err.Error()
+}
+
+func (w wrappedErrors) Unwrap() error {
+	return w.err
+}
+
+func TestCatalogNonComparableError(t *testing.T) {
+	inner := ValidationErrors{NewValidationError(ErrValidationMin)}
+	catalog := Catalog{
+		wrappedErrors{err: ErrValidationMax}: "wrapped max",
+		ErrValidationNonZero:                 "{field} is required",
+	}
+	e := ValidationError{Field: "N", Err: wrappedErrors{err: inner}}
+	require.NotPanics(t, func() {
+		require.Equal(t, inner.Error(), catalog.Translate(e))
+	})
+
+	e = ValidationError{Field: "N", Err: wrappedErrors{err: ErrValidationMax}}
+	require.Equal(t, "wrapped max", catalog.Translate(e))
+}
+
+func TestMessagesTranslator(t *testing.T) {
+	admin := true
+	err := Validate(Account{Login: "alice", Age: 20, Password: "password1", Admin: &admin})
+	require.NoError(t, err)
+
+	err = Validate(Account{Login: "al", Age: 20, Password: "password1", Admin: &admin})
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	msgs := errs.Messages(RussianCatalog)
+	require.Equal(t, []Message{
+		{
+			Field:   "Login",
+			Rule:    "len",
+			Params:  []string{"5"},
+			Message: "длина Login должна быть 5 символов",
+		},
+	}, msgs)
+}
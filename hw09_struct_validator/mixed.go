@@ -4,15 +4,50 @@ import "reflect"
 
 type InValidation struct{}
 
-func (InValidation) Validate(v interface{}, args ...string) error {
-	refType := reflect.TypeOf(v)
-	var validator Validator
-	if refType.Kind() == reflect.String {
-		validator = StringRangeValidation{}
-	} else {
-		validator = IntRangeValidation{}
+// inValidation is InValidation with parsed args, ints is nil if args are not integers.
+type inValidation struct {
+	args []string
+	ints Validator
+	// intsErr is error of parsing args as integers, it is reported for integer values only
+	intsErr error
+}
+
+// Validate checks strings by StringRangeValidation and integers by IntRangeValidation,
+// items of slices are checked by their own types.
+func (validator InValidation) Validate(v interface{}, args ...string) error {
+	return validateCompiled(validator, v, args)
+}
+
+// Compile parses args as integers, non-integer args are allowed for strings only.
+func (validator InValidation) Compile(args ...string) (Validator, error) {
+	if len(args) < 1 {
+		return nil, NewParseValidatorError(ErrExpectedAtLeastOneParameter)
+	}
+	ints, err := IntRangeValidation{}.Compile(args...)
+	return inValidation{args: args, ints: ints, intsErr: err}, nil
+}
+
+func (validator inValidation) Validate(v interface{}, _ ...string) error {
+	refValue := reflect.ValueOf(v)
+	//nolint: exhaustive
+	switch refValue.Kind() {
+	case reflect.String:
+		return StringRangeValidation{}.Validate(v, validator.args...)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if validator.intsErr != nil {
+			return validator.intsErr
+		}
+		return validator.ints.Validate(v)
+	case reflect.Slice:
+		if err := validateItems(refValue, func(item interface{}) error {
+			return validator.Validate(item)
+		}); err != nil {
+			return err
+		}
+	default:
+		return NewParseValidatorError(ErrInappropriateType)
 	}
-	return validator.Validate(v, args...)
+	return nil
 }
 
-var _ Validator = (*InValidation)(nil)
+var _ Compiler = (*InValidation)(nil)
@@ -15,6 +15,12 @@ func TestInValidation_Validate(t *testing.T) {
 	}{
 		{12, []string{"13", "15"}, ErrValidationIntRange},
 		{"12", []string{"13", "15"}, ErrValidationStringRange},
+		{12.5, []string{"13", "15"}, ErrInappropriateType},
+		{true, []string{"true"}, ErrInappropriateType},
+		{[]interface{}{"13", 15}, []string{"13", "15"}, nil},
+		{[]interface{}{"13", 14}, []string{"13", "15"}, ErrValidationIntRange},
+		{[]string{"13", "14"}, []string{"13", "15"}, ErrValidationStringRange},
+		{15, []string{"13", "15"}, nil},
 	}
 
 	for _, tc := range tests {
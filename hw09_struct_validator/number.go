@@ -13,10 +13,26 @@ type (
 	IntRangeValidation struct{}
 )
 
+// intLimitValidation is min or max validator with parsed limit.
+type intLimitValidation struct {
+	limit          int64
+	isValid        func(v, limit int64) bool
+	errorFormatter func(v, limit int64) error
+}
+
+// intSetValidation is IntRangeValidation with parsed args.
+type intSetValidation struct {
+	values []int64
+	args   []string
+}
+
 func (validator MinValidation) Validate(v interface{}, args ...string) error {
-	return validateMinMax(
-		validator,
-		v,
+	return validateCompiled(validator, v, args)
+}
+
+// Compile parses limit of min validator.
+func (validator MinValidation) Compile(args ...string) (Validator, error) {
+	return compileIntLimit(
 		args,
 		func(v, min int64) bool {
 			return v >= min
@@ -28,9 +44,12 @@ func (validator MinValidation) Validate(v interface{}, args ...string) error {
 }
 
 func (validator MaxValidation) Validate(v interface{}, args ...string) error {
-	return validateMinMax(
-		validator,
-		v,
+	return validateCompiled(validator, v, args)
+}
+
+// Compile parses limit of max validator.
+func (validator MaxValidation) Compile(args ...string) (Validator, error) {
+	return compileIntLimit(
 		args,
 		func(v, max int64) bool {
 			return v <= max
@@ -41,36 +60,34 @@ func (validator MaxValidation) Validate(v interface{}, args ...string) error {
 	)
 }
 
-func validateMinMax(
-	validator Validator,
-	v interface{},
+func compileIntLimit(
 	args []string,
-	isValid func(v, max int64) bool,
-	errorFormatter func(v, max int64) error,
-) error {
+	isValid func(v, limit int64) bool,
+	errorFormatter func(v, limit int64) error,
+) (Validator, error) {
 	if len(args) != 1 {
-		return NewParseValidatorError(ErrExpectedOneParameter)
+		return nil, NewParseValidatorError(ErrExpectedOneParameter)
 	}
 	mm, err := strconv.Atoi(args[0])
 	if err != nil {
-		return NewParseValidatorError(fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error()))
+		return nil, NewParseValidatorError(fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error()))
 	}
+	return intLimitValidation{limit: int64(mm), isValid: isValid, errorFormatter: errorFormatter}, nil
+}
+
+func (validator intLimitValidation) Validate(v interface{}, _ ...string) error {
 	refValue := reflect.ValueOf(v)
 	//nolint: exhaustive
 	switch refValue.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if !isValid(refValue.Int(), int64(mm)) {
-			return NewValidationError(errorFormatter(refValue.Int(), int64(mm)))
+		if !validator.isValid(refValue.Int(), validator.limit) {
+			return NewValidationError(validator.errorFormatter(refValue.Int(), validator.limit))
 		}
 	case reflect.Slice:
-		for i := 0; i < refValue.Len(); i++ {
-			item := refValue.Index(i)
-			if !item.CanInterface() {
-				return fmt.Errorf("%w: %s", ErrInappropriateType, item.Type())
-			}
-			if err := validator.Validate(item.Interface(), args...); err != nil {
-				return err
-			}
+		if err := validateItems(refValue, func(item interface{}) error {
+			return validator.Validate(item)
+		}); err != nil {
+			return err
 		}
 	default:
 		return NewParseValidatorError(ErrInappropriateType)
@@ -79,22 +96,31 @@ func validateMinMax(
 }
 
 func (validator IntRangeValidation) Validate(v interface{}, args ...string) error {
+	return validateCompiled(validator, v, args)
+}
+
+// Compile parses integers of range.
+func (validator IntRangeValidation) Compile(args ...string) (Validator, error) {
 	if len(args) < 1 {
-		return NewParseValidatorError(ErrExpectedAtLeastOneParameter)
+		return nil, NewParseValidatorError(ErrExpectedAtLeastOneParameter)
 	}
-	arr := []int64{}
+	arr := make([]int64, 0, len(args))
 	for _, arg := range args {
 		n, err := strconv.Atoi(arg)
 		if err != nil {
-			return NewParseValidatorError(fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error()))
+			return nil, NewParseValidatorError(fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error()))
 		}
 		arr = append(arr, int64(n))
 	}
+	return intSetValidation{values: arr, args: args}, nil
+}
+
+func (validator intSetValidation) Validate(v interface{}, _ ...string) error {
 	refValue := reflect.ValueOf(v)
 	//nolint: exhaustive
 	switch refValue.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		for _, val := range arr {
+		for _, val := range validator.values {
 			if val == refValue.Int() {
 				return nil
 			}
@@ -104,18 +130,14 @@ func (validator IntRangeValidation) Validate(v interface{}, args ...string) erro
 				"%w: %d not in range (%s)",
 				ErrValidationIntRange,
 				refValue.Int(),
-				strings.Join(args, ", "),
+				strings.Join(validator.args, ", "),
 			),
 		)
 	case reflect.Slice:
-		for i := 0; i < refValue.Len(); i++ {
-			item := refValue.Index(i)
-			if !item.CanInterface() {
-				return fmt.Errorf("%w: %s", ErrInappropriateType, item.Type())
-			}
-			if err := validator.Validate(item.Interface(), args...); err != nil {
-				return err
-			}
+		if err := validateItems(refValue, func(item interface{}) error {
+			return validator.Validate(item)
+		}); err != nil {
+			return err
 		}
 	default:
 		return NewParseValidatorError(ErrInappropriateType)
@@ -124,7 +146,7 @@ func (validator IntRangeValidation) Validate(v interface{}, args ...string) erro
 }
 
 var (
-	_ Validator = (*MinValidation)(nil)
-	_ Validator = (*MaxValidation)(nil)
-	_ Validator = (*IntRangeValidation)(nil)
+	_ Compiler = (*MinValidation)(nil)
+	_ Compiler = (*MaxValidation)(nil)
+	_ Compiler = (*IntRangeValidation)(nil)
 )
@@ -0,0 +1,178 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Compiler is implemented by validators parsing args, e.g. regexp or min.
+// Compile is called once per field of struct type, it returns validator with parsed args
+// which is called without args. Malformed args are reported as ParseValidatorError.
+type Compiler interface {
+	Validator
+	Compile(args ...string) (Validator, error)
+}
+
+// ArgsChecker is implemented by validators using args as is, e.g. email or ip.
+// CheckArgs is called when plan of struct type is compiled, so malformed args are reported
+// even if validator is not called, e.g. for nil pointer.
+type ArgsChecker interface {
+	CheckArgs(args ...string) error
+}
+
+// FieldReferrer is implemented by context validators referring to other fields of struct, e.g. gtfield.
+// Fields returns names of referred fields from checked args, they are looked up in struct type.
+type FieldReferrer interface {
+	Fields(args ...string) []string
+}
+
+// validateCompiled compiles validator and validates v, it is Validate of validators implementing Compiler.
+func validateCompiled(validator Compiler, v interface{}, args []string) error {
+	compiled, err := validator.Compile(args...)
+	if err != nil {
+		return err
+	}
+	return compiled.Validate(v)
+}
+
+// structPlan is compiled validation of struct type.
+type structPlan struct {
+	fields []fieldPlan
+	// err is ParseValidatorError of malformed tag, it is returned for every value of type
+	err error
+}
+
+// fieldPlan is compiled validation of field.
+type fieldPlan struct {
+	index int
+	field reflect.StructField
+	// embedded struct without tag, its fields are promoted
+	embedded bool
+	// redact hides value of field in errors
+	redact bool
+	rules  []rule
+}
+
+// rule is validator of tag, validator is nil for NestedTag.
+type rule struct {
+	tag       TagData
+	validator Validator
+	// args are passed to validator which does not implement Compiler
+	args []string
+}
+
+// plan returns cached plan of struct type t, plan is compiled on the first call.
+func (s *StructValidator) plan(t reflect.Type) *structPlan {
+	s.mu.RLock()
+	p, ok := s.plans[t]
+	generation := s.generation
+	s.mu.RUnlock()
+	if ok {
+		return p
+	}
+	p = s.compile(t)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// plan compiled with replaced validator is not cached
+	if s.generation == generation {
+		s.plans[t] = p
+	}
+	return p
+}
+
+// compile compiles plan of struct type t.
+func (s *StructValidator) compile(t reflect.Type) *structPlan {
+	p := &structPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		v, ok := field.Tag.Lookup("validate")
+		if !ok {
+			if field.Anonymous && isStruct(field.Type) {
+				p.fields = append(p.fields, fieldPlan{index: i, field: field, embedded: true})
+			}
+			continue
+		}
+		fp := fieldPlan{index: i, field: field, redact: field.Tag.Get(RedactTag) == "true"}
+		for _, tag := range ParseTag(v) {
+			r, err := s.compileRule(t, tag)
+			if err != nil {
+				p.err = err
+				return p
+			}
+			fp.rules = append(fp.rules, r)
+		}
+		p.fields = append(p.fields, fp)
+	}
+	return p
+}
+
+// compileRule finds validator of tag of field of struct type t and compiles it.
+func (s *StructValidator) compileRule(t reflect.Type, tag TagData) (rule, error) {
+	if tag.Name == NestedTag {
+		return rule{tag: tag}, nil
+	}
+	validator, ok := s.lookup(tag.Name)
+	if !ok {
+		return rule{}, tagError(tag, fmt.Errorf("%w: %s", ErrValidatorDoesNotExist, tag.Name))
+	}
+	if c, ok := validator.(ArgsChecker); ok {
+		if err := c.CheckArgs(tag.Args...); err != nil {
+			return rule{}, tagError(tag, err)
+		}
+	}
+	if fr, ok := validator.(FieldReferrer); ok {
+		for _, name := range fr.Fields(tag.Args...) {
+			if err := lookupField(t, name); err != nil {
+				return rule{}, tagError(tag, err)
+			}
+		}
+	}
+	c, ok := validator.(Compiler)
+	if !ok {
+		return rule{tag: tag, validator: validator, args: tag.Args}, nil
+	}
+	compiled, err := c.Compile(tag.Args...)
+	if err != nil {
+		return rule{}, tagError(tag, err)
+	}
+	return rule{tag: tag, validator: compiled}, nil
+}
+
+// tagError returns err as ParseValidatorError of tag.
+func tagError(tag TagData, err error) ParseValidatorError {
+	var pe ParseValidatorError
+	if !errors.As(err, &pe) {
+		pe = NewParseValidatorError(err)
+	}
+	if pe.Tag == "" {
+		pe.Tag = tag.OriginalTag
+	}
+	return pe
+}
+
+// lookupField checks that exported field name exists in struct type t like FieldContext.Sibling.
+// Fields of interfaces are known at run time only, so they are not checked.
+func lookupField(t reflect.Type, name string) error {
+	for _, part := range strings.Split(name, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Interface {
+			return nil
+		}
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("%w: %s", ErrFieldDoesNotExist, name)
+		}
+		field, found := t.FieldByName(part)
+		if !found || field.PkgPath != "" {
+			return fmt.Errorf("%w: %s", ErrFieldDoesNotExist, name)
+		}
+		t = field.Type
+	}
+	return nil
+}
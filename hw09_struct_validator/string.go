@@ -14,32 +14,47 @@ type (
 	StringLenValidation   struct{}
 )
 
+// regexpValidation is RegexpValidation with compiled regexp.
+type regexpValidation struct {
+	re *regexp.Regexp
+}
+
+// stringLenValidation is StringLenValidation with parsed length.
+type stringLenValidation struct {
+	length int
+}
+
 func (validator RegexpValidation) Validate(v interface{}, args ...string) error {
+	return validateCompiled(validator, v, args)
+}
+
+// Compile compiles regexp of validator.
+func (validator RegexpValidation) Compile(args ...string) (Validator, error) {
 	if len(args) != 1 {
-		return NewParseValidatorError(ErrExpectedOneParameter)
+		return nil, NewParseValidatorError(ErrExpectedOneParameter)
 	}
 	re, err := regexp.Compile(args[0])
 	if err != nil {
-		return NewParseValidatorError(fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error()))
+		return nil, NewParseValidatorError(fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error()))
 	}
+	return regexpValidation{re: re}, nil
+}
+
+func (validator regexpValidation) Validate(v interface{}, _ ...string) error {
 	refValue := reflect.ValueOf(v)
 	//nolint: exhaustive
 	switch refValue.Kind() {
 	case reflect.String:
-		if !re.MatchString(refValue.String()) {
+		if !validator.re.MatchString(refValue.String()) {
 			return NewValidationError(
-				fmt.Errorf("%w: '%s'", ErrValidationRegexp, re.String()),
+				fmt.Errorf("%w: '%s'", ErrValidationRegexp, validator.re.String()),
 			)
 		}
 	case reflect.Slice:
-		for i := 0; i < refValue.Len(); i++ {
-			item := refValue.Index(i)
-			if !item.CanInterface() {
-				return NewValidationError(fmt.Errorf("%w: %s", ErrInappropriateType, item.Type()))
-			}
-			if err := validator.Validate(item.Interface(), args...); err != nil {
-				return err
-			}
+		if err := validateItems(refValue, func(item interface{}) error {
+			return validator.Validate(item)
+		}); err != nil {
+			return err
 		}
 	default:
 		return NewParseValidatorError(ErrInappropriateType)
@@ -47,10 +62,18 @@ func (validator RegexpValidation) Validate(v interface{}, args ...string) error
 	return nil
 }
 
-func (validator StringRangeValidation) Validate(v interface{}, args ...string) error {
+// CheckArgs checks that at least one string is given.
+func (validator StringRangeValidation) CheckArgs(args ...string) error {
 	if len(args) < 1 {
 		return NewParseValidatorError(ErrExpectedAtLeastOneParameter)
 	}
+	return nil
+}
+
+func (validator StringRangeValidation) Validate(v interface{}, args ...string) error {
+	if err := validator.CheckArgs(args...); err != nil {
+		return err
+	}
 	refValue := reflect.ValueOf(v)
 	//nolint: exhaustive
 	switch refValue.Kind() {
@@ -69,14 +92,10 @@ func (validator StringRangeValidation) Validate(v interface{}, args ...string) e
 			),
 		)
 	case reflect.Slice:
-		for i := 0; i < refValue.Len(); i++ {
-			item := refValue.Index(i)
-			if !item.CanInterface() {
-				return NewValidationError(fmt.Errorf("%w: %s", ErrInappropriateType, item.Type()))
-			}
-			if err := validator.Validate(item.Interface(), args...); err != nil {
-				return err
-			}
+		if err := validateItems(refValue, func(item interface{}) error {
+			return validator.Validate(item, args...)
+		}); err != nil {
+			return err
 		}
 	default:
 		return NewParseValidatorError(ErrInappropriateType)
@@ -85,16 +104,26 @@ func (validator StringRangeValidation) Validate(v interface{}, args ...string) e
 }
 
 func (validator StringLenValidation) Validate(v interface{}, args ...string) error {
+	return validateCompiled(validator, v, args)
+}
+
+// Compile parses expected length.
+func (validator StringLenValidation) Compile(args ...string) (Validator, error) {
 	if len(args) != 1 {
-		return NewParseValidatorError(ErrExpectedOneParameter)
+		return nil, NewParseValidatorError(ErrExpectedOneParameter)
 	}
 	length, err := strconv.Atoi(args[0])
 	if err != nil {
-		return NewParseValidatorError(fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error()))
+		return nil, NewParseValidatorError(fmt.Errorf("%w: %s", ErrInvalidParameter, err.Error()))
 	}
 	if length < 0 {
-		return NewParseValidatorError(fmt.Errorf("%w: length < 0", ErrInvalidParameter))
+		return nil, NewParseValidatorError(fmt.Errorf("%w: length < 0", ErrInvalidParameter))
 	}
+	return stringLenValidation{length: length}, nil
+}
+
+func (validator stringLenValidation) Validate(v interface{}, _ ...string) error {
+	length := validator.length
 	refValue := reflect.ValueOf(v)
 	//nolint: exhaustive
 	switch refValue.Kind() {
@@ -111,14 +140,10 @@ func (validator StringLenValidation) Validate(v interface{}, args ...string) err
 			)
 		}
 	case reflect.Slice:
-		for i := 0; i < refValue.Len(); i++ {
-			item := refValue.Index(i)
-			if !item.CanInterface() {
-				return NewValidationError(fmt.Errorf("%w: %s", ErrInappropriateType, item.Type()))
-			}
-			if err := validator.Validate(item.Interface(), args...); err != nil {
-				return err
-			}
+		if err := validateItems(refValue, func(item interface{}) error {
+			return validator.Validate(item)
+		}); err != nil {
+			return err
 		}
 	default:
 		return NewParseValidatorError(ErrInappropriateType)
@@ -127,7 +152,7 @@ func (validator StringLenValidation) Validate(v interface{}, args ...string) err
 }
 
 var (
-	_ Validator = (*RegexpValidation)(nil)
-	_ Validator = (*StringLenValidation)(nil)
-	_ Validator = (*StringRangeValidation)(nil)
+	_ Compiler    = (*RegexpValidation)(nil)
+	_ Compiler    = (*StringLenValidation)(nil)
+	_ ArgsChecker = (*StringRangeValidation)(nil)
 )
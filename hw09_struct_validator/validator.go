@@ -1,57 +1,313 @@
 package hw09structvalidator
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 )
 
+// NestedTag marks field which is validated recursively: struct, pointer to struct,
+// slice, array or map of structs. Embedded structs without validate tag are validated automatically.
+const NestedTag = "nested"
+
+// RedactTag is a struct tag hiding value of field in validation errors, e.g. `redact:"true"`.
+const RedactTag = "redact"
+
+// RedactedValue replaces value of redacted field in ValidationError.
+const RedactedValue = "***"
+
 type Validator interface {
 	Validate(v interface{}, args ...string) error
 }
 
-var mapping = map[string]Validator{
-	"in":     InValidation{},
-	"min":    MinValidation{},
-	"max":    MaxValidation{},
-	"regexp": RegexpValidation{},
-	"len":    StringLenValidation{},
+// ContextValidator is implemented by validators which need other fields of struct, e.g. gtfield.
+// ValidateContext is called instead of Validate with not dereferenced value of field.
+type ContextValidator interface {
+	Validator
+	ValidateContext(ctx FieldContext, args ...string) error
+}
+
+// NilValidator is implemented by validators checking nil pointers, e.g. nonzero.
+// Other validators skip nil pointers.
+type NilValidator interface {
+	ValidateNil(args ...string) error
+}
+
+// builtinValidators returns validators available in every StructValidator.
+func builtinValidators() map[string]Validator {
+	return map[string]Validator{
+		"in":       InValidation{},
+		"min":      MinValidation{},
+		"max":      MaxValidation{},
+		"regexp":   RegexpValidation{},
+		"len":      StringLenValidation{},
+		"email":    EmailValidation{},
+		"uuid":     UUIDValidation{},
+		"url":      URLValidation{},
+		"ip":       IPValidation{},
+		"nonzero":  NonZeroValidation{},
+		"oneof":    OneOfValidation{},
+		"datetime": DatetimeValidation{},
+
+		"eqfield":     CompareFieldValidation{Op: OpEq},
+		"nefield":     CompareFieldValidation{Op: OpNe},
+		"gtfield":     CompareFieldValidation{Op: OpGt},
+		"gtefield":    CompareFieldValidation{Op: OpGte},
+		"ltfield":     CompareFieldValidation{Op: OpLt},
+		"ltefield":    CompareFieldValidation{Op: OpLte},
+		"required_if": RequiredIfValidation{},
+	}
+}
+
+// StructValidator validates structs by validators of its own registry.
+// Validation plans of struct types are compiled once and cached, see Compiler.
+type StructValidator struct {
+	mu         sync.RWMutex
+	validators map[string]Validator
+	plans      map[reflect.Type]*structPlan
+	// generation is changed by Register, so plans compiled with old validators are not cached
+	generation uint64
 }
 
+// NewValidator creates StructValidator with built-in validators.
+func NewValidator() *StructValidator {
+	return &StructValidator{validators: builtinValidators(), plans: map[reflect.Type]*structPlan{}}
+}
+
+var defaultValidator = NewValidator()
+
+// RegisterValidator adds validator used by Validate, see StructValidator.Register.
+func RegisterValidator(name string, v Validator) {
+	defaultValidator.Register(name, v)
+}
+
+// Validate validates v by validators registered with RegisterValidator, see StructValidator.Validate.
 func Validate(v interface{}) error {
-	r := reflect.TypeOf(v)
-	if r.Kind() != reflect.Struct {
-		return ErrInvalidType
+	return defaultValidator.Validate(v)
+}
+
+// Register adds validator for tag name, validator with the same name is replaced.
+// Cached plans are dropped, so they are compiled with the new validator.
+// It panics if name is empty, reserved or contains tag separators, or if v is nil.
+func (s *StructValidator) Register(name string, v Validator) {
+	if name == "" || name == NestedTag || strings.ContainsAny(name, "|:,") {
+		panic("hw09structvalidator: invalid validator name " + name)
+	}
+	if v == nil {
+		panic("hw09structvalidator: validator " + name + " is nil")
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.validators[name] = v
+	s.plans = map[reflect.Type]*structPlan{}
+	s.generation++
+}
+
+func (s *StructValidator) lookup(name string) (Validator, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.validators[name]
+	return v, ok
+}
+
+// Validate validates exported fields of struct v (or pointer to struct) by validate tags.
+// Field of validation errors in nested structs is a path like Address.Zip or Items[2].Name.
+func (s *StructValidator) Validate(v interface{}) error {
 	rv := reflect.ValueOf(v)
-	var errs ValidationErrors
-	for i := 0; i < r.NumField(); i++ {
-		v, ok := r.Field(i).Tag.Lookup("validate")
-		if !ok || !rv.Field(i).CanInterface() {
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return ErrInvalidType
+	}
+	w := walker{validators: s, visited: map[visit]bool{}}
+	if err := w.validateNested(reflect.ValueOf(v), "", ""); err != nil {
+		return err
+	}
+	if len(w.errs) == 0 {
+		return nil
+	}
+	return w.errs
+}
+
+// visit identifies pointer, slice or map being validated.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// walker validates struct with nested values and collects errors of validation.
+type walker struct {
+	validators *StructValidator
+	errs       ValidationErrors
+	// visited values are on the current path, they are skipped to break cycles
+	visited map[visit]bool
+}
+
+// validateStruct validates fields of struct by plan of its type, prefix is prepended to names of fields.
+func (w *walker) validateStruct(rv reflect.Value, prefix string) error {
+	p := w.validators.plan(rv.Type())
+	if p.err != nil {
+		return p.err // it seems, someone needs to fix validator declaration
+	}
+	for _, fp := range p.fields {
+		value := rv.Field(fp.index)
+		if fp.embedded {
+			// fields of embedded struct are promoted, so they have no prefix
+			if err := w.validateNested(value, prefix, prefix); err != nil {
+				return err
+			}
 			continue
 		}
-		tags := ParseTag(v)
-		for _, tag := range tags {
-			err := validateByTag(tag, rv.Field(i))
+		path := prefix + fp.field.Name
+		for _, r := range fp.rules {
+			var err error
+			if r.validator == nil {
+				err = w.validateNested(value, path, path+".")
+			} else {
+				err = validateRule(r, FieldContext{Parent: rv, Field: fp.field, Value: value, Path: path})
+			}
 			switch e := err.(type) { // nolint:errorlint
 			case ValidationError:
-				e.Field = r.Field(i).Name
-				errs = append(errs, e) // collect error of validation
+				e.Field = path
+				e.Rule = r.tag.Name
+				e.Params = r.tag.Args
+				if e.Value == nil || fp.redact {
+					// value of failed item of slice is set by validator
+					e.Value = fieldValue(value, fp.redact)
+				}
+				if fp.redact {
+					e.Err = redactError(e.Err)
+				}
+				w.errs = append(w.errs, e) // collect error of validation
 			case ParseValidatorError:
-				e.Tag = tag.OriginalTag
-				return e // it seems, someone needs to fix validator declaration
+				if e.Tag == "" {
+					e.Tag = r.tag.OriginalTag
+				}
+				return e
 			}
 		}
 	}
-	if len(errs) == 0 {
-		return nil
+	return nil
+}
+
+// validateNested validates struct value or struct items of val.
+// Path is a name of val in errors, prefix is prepended to fields of struct.
+func (w *walker) validateNested(val reflect.Value, path, prefix string) error {
+	//nolint: exhaustive
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+		if val.Kind() != reflect.Interface {
+			key := visit{ptr: val.Pointer(), typ: val.Type()}
+			if w.visited[key] {
+				return nil
+			}
+			w.visited[key] = true
+			defer delete(w.visited, key)
+		}
+	}
+
+	//nolint: exhaustive
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return w.validateNested(val.Elem(), path, prefix)
+	case reflect.Struct:
+		return w.validateStruct(val, prefix)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if err := w.validateNested(val.Index(i), itemPath, itemPath+"."); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, key := range keys {
+			itemPath := fmt.Sprintf("%s[%v]", path, key)
+			if err := w.validateNested(val.MapIndex(key), itemPath, itemPath+"."); err != nil {
+				return err
+			}
+		}
+	default:
+		return NewParseValidatorError(fmt.Errorf("%w: %s", ErrInappropriateType, val.Type()))
+	}
+	return nil
+}
+
+// isStruct reports if t is a struct or pointer to struct.
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
-	return errs
+	return t.Kind() == reflect.Struct
 }
 
-func validateByTag(tag TagData, val reflect.Value) error {
-	validator, ok := mapping[tag.Name]
-	if !ok {
-		return NewParseValidatorError(fmt.Errorf("%w: %s", ErrValidatorDoesNotExist, tag.Name))
+// fieldValue returns value of field for ValidationError, pointers are dereferenced.
+func fieldValue(val reflect.Value, redact bool) interface{} {
+	if redact {
+		return RedactedValue
+	}
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	return val.Interface()
+}
+
+func validateRule(r rule, ctx FieldContext) error {
+	if cv, ok := r.validator.(ContextValidator); ok {
+		return cv.ValidateContext(ctx, r.args...)
+	}
+	val := ctx.Value
+	// value of pointer is validated, nil pointer is skipped
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			if nv, ok := r.validator.(NilValidator); ok {
+				return nv.ValidateNil(r.args...)
+			}
+			return nil
+		}
+		val = val.Elem()
+	}
+	return r.validator.Validate(val.Interface(), r.args...)
+}
+
+// redactError returns error wrapping the innermost error of err, e.g. ErrValidationStringLen,
+// messages of validators may contain the value, so they are dropped.
+func redactError(err error) error {
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			break
+		}
+		err = inner
+	}
+	return fmt.Errorf("%w: %s", err, RedactedValue)
+}
+
+// validateItems calls validate for each item of slice refValue, failed item is a Value of ValidationError.
+func validateItems(refValue reflect.Value, validate func(item interface{}) error) error {
+	for i := 0; i < refValue.Len(); i++ {
+		item := refValue.Index(i)
+		if !item.CanInterface() {
+			return NewValidationError(fmt.Errorf("%w: %s", ErrInappropriateType, item.Type()))
+		}
+		err := validate(item.Interface())
+		if e, ok := err.(ValidationError); ok && e.Value == nil { // nolint:errorlint
+			e.Value = item.Interface()
+			return e
+		}
+		if err != nil {
+			return err
+		}
 	}
-	return validator.Validate(val.Interface(), tag.Args...)
+	return nil
 }
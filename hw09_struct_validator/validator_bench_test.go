@@ -0,0 +1,90 @@
+package hw09structvalidator
+
+import (
+	"reflect"
+	"testing"
+)
+
+type benchUser struct {
+	ID     string   `validate:"len:36"`
+	Name   string   `validate:"regexp:^[A-Z][a-z]+$"`
+	Age    int      `validate:"min:18|max:50"`
+	Email  string   `validate:"regexp:^\\w+@\\w+\\.\\w+$"`
+	Role   string   `validate:"in:admin,stuff"`
+	Phones []string `validate:"len:11"`
+	Level  int      `validate:"oneof:1,2,3"`
+}
+
+var benchValue = benchUser{
+	ID:     "123e4567-e89b-12d3-a456-426614174000",
+	Name:   "Alice",
+	Age:    30,
+	Email:  "alice@example.com",
+	Role:   "admin",
+	Phones: []string{"79991234567", "79997654321"},
+	Level:  2,
+}
+
+// validatePerValue validates flat struct like StructValidator did before caching of plans:
+// tags are parsed and args are compiled for every value.
+func validatePerValue(s *StructValidator, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	var errs ValidationErrors
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok || !rv.Field(i).CanInterface() {
+			continue
+		}
+		for _, td := range ParseTag(tag) {
+			validator, ok := s.lookup(td.Name)
+			if !ok {
+				return ErrValidatorDoesNotExist
+			}
+			switch e := validator.Validate(rv.Field(i).Interface(), td.Args...).(type) { // nolint:errorlint
+			case ValidationError:
+				e.Field = field.Name
+				errs = append(errs, e)
+			case ParseValidatorError:
+				return e
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// go test -run=^$ -bench=Validate -benchmem .
+func BenchmarkValidate(b *testing.B) {
+	b.Run("cached", func(b *testing.B) {
+		v := NewValidator()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := v.Validate(benchValue); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("per_value", func(b *testing.B) {
+		v := NewValidator()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := validatePerValue(v, benchValue); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("compile_and_validate", func(b *testing.B) {
+		v := NewValidator()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			// plan is compiled for every value, it is the cost of the first call for type
+			v.plans = map[reflect.Type]*structPlan{}
+			if err := v.Validate(benchValue); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -2,7 +2,10 @@ package hw09structvalidator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -163,3 +166,337 @@ func TestError(t *testing.T) {
 	}
 	require.ErrorIs(t, Validate("123"), ErrInvalidType)
 }
+
+type (
+	Address struct {
+		Zip  string `validate:"len:5"`
+		City string `validate:"in:Moscow,Kazan"`
+	}
+
+	Item struct {
+		Name  string `validate:"len:3"`
+		Count int    `validate:"min:1"`
+	}
+
+	Base struct {
+		ID string `validate:"len:4"`
+	}
+
+	Order struct {
+		Base
+		Address  Address          `validate:"nested"`
+		Billing  *Address         `validate:"nested"`
+		Items    []Item           `validate:"nested"`
+		Tags     map[string]*Item `validate:"nested"`
+		Discount *int             `validate:"min:0|max:100"`
+		Plain    Address
+	}
+
+	Node struct {
+		Name     string  `validate:"len:1"`
+		Next     *Node   `validate:"nested"`
+		Children []*Node `validate:"nested"`
+	}
+)
+
+func TestValidateNested(t *testing.T) {
+	valid := func() Order {
+		discount := 10
+		return Order{
+			Base:     Base{ID: "1234"},
+			Address:  Address{Zip: "12345", City: "Kazan"},
+			Items:    []Item{{"abc", 1}, {"def", 2}},
+			Tags:     map[string]*Item{"a": {"tag", 1}, "b": nil},
+			Discount: &discount,
+			Plain:    Address{Zip: "1"},
+		}
+	}
+	require.NoError(t, Validate(valid()))
+	order := valid()
+	require.NoError(t, Validate(&order))
+
+	order.ID = "1"
+	order.Address.Zip = "1"
+	order.Billing = &Address{Zip: "12345", City: "Omsk"}
+	order.Items[1] = Item{"de", 0}
+	order.Tags["c"] = &Item{"x", 1}
+	discount := 101
+	order.Discount = &discount
+	err := Validate(order)
+
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	exp := []struct {
+		field string
+		err   error
+	}{
+		{"ID", ErrValidationStringLen},
+		{"Address.Zip", ErrValidationStringLen},
+		{"Billing.City", ErrValidationStringRange},
+		{"Items[1].Name", ErrValidationStringLen},
+		{"Items[1].Count", ErrValidationMin},
+		{"Tags[c].Name", ErrValidationStringLen},
+		{"Discount", ErrValidationMax},
+	}
+	require.Len(t, errs, len(exp))
+	for i, e := range exp {
+		require.Equal(t, e.field, errs[i].Field)
+		require.ErrorIs(t, errs[i], e.err)
+	}
+}
+
+func TestValidateNestedCycle(t *testing.T) {
+	root := &Node{Name: "r"}
+	child := &Node{Name: "cc", Next: root}
+	root.Next = root
+	root.Children = []*Node{child, root}
+
+	err := Validate(root)
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 1)
+	require.Equal(t, "Children[0].Name", errs[0].Field)
+}
+
+func TestValidateNestedError(t *testing.T) {
+	err := Validate(struct {
+		Count int `validate:"nested"`
+	}{})
+	var parseErr ParseValidatorError
+	require.ErrorAs(t, err, &parseErr)
+	require.ErrorIs(t, err, ErrInappropriateType)
+	require.Equal(t, "nested", parseErr.Tag)
+
+	err = Validate(struct {
+		Inner struct {
+			Age int `validate:"min"`
+		} `validate:"nested"`
+	}{})
+	require.ErrorAs(t, err, &parseErr)
+	require.ErrorIs(t, err, ErrExpectedOneParameter)
+	require.Equal(t, "min", parseErr.Tag)
+
+	require.ErrorIs(t, Validate((*Order)(nil)), ErrInvalidType)
+}
+
+type evenValidation struct{}
+
+func (evenValidation) Validate(v interface{}, _ ...string) error {
+	n, ok := v.(int)
+	if !ok {
+		return NewParseValidatorError(ErrInappropriateType)
+	}
+	if n%2 != 0 {
+		return NewValidationError(errOdd)
+	}
+	return nil
+}
+
+var errOdd = errors.New("odd number")
+
+func TestRegisterValidator(t *testing.T) {
+	type Pair struct {
+		N     int     `validate:"even"`
+		Email string  `validate:"email"`
+		Ptr   *string `validate:"nonzero"`
+	}
+
+	v := NewValidator()
+	v.Register("even", evenValidation{})
+	empty := ""
+	err := v.Validate(Pair{N: 3, Email: "a@b.c", Ptr: &empty})
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[0], errOdd)
+	require.ErrorIs(t, errs[1], ErrValidationNonZero)
+
+	err = v.Validate(Pair{N: 2, Email: "a@b.c"})
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 1)
+	require.Equal(t, "Ptr", errs[0].Field)
+
+	// registry of instance is not shared
+	err = NewValidator().Validate(Pair{N: 2})
+	require.ErrorIs(t, err, ErrValidatorDoesNotExist)
+	require.ErrorIs(t, Validate(Pair{N: 2}), ErrValidatorDoesNotExist)
+
+	// registered validator replaces built-in one
+	v.Register("email", evenValidation{})
+	err = v.Validate(Pair{N: 2, Email: "a@b.c", Ptr: &empty})
+	require.ErrorIs(t, err, ErrInappropriateType)
+
+	for _, name := range []string{"", "nested", "a|b", "a:b", "a,b"} {
+		require.Panics(t, func() {
+			v.Register(name, evenValidation{})
+		}, name)
+	}
+	require.Panics(t, func() {
+		v.Register("nil", nil)
+	})
+}
+
+func TestRegisterValidatorGlobal(t *testing.T) {
+	type Even struct {
+		N int `validate:"even_global"`
+	}
+	RegisterValidator("even_global", evenValidation{})
+	defer func() {
+		defaultValidator.mu.Lock()
+		delete(defaultValidator.validators, "even_global")
+		defaultValidator.plans = map[reflect.Type]*structPlan{}
+		defaultValidator.mu.Unlock()
+	}()
+	require.NoError(t, Validate(Even{N: 2}))
+	var errs ValidationErrors
+	require.ErrorAs(t, Validate(Even{N: 1}), &errs)
+	require.ErrorIs(t, errs[0], errOdd)
+}
+
+// countingValidation counts compilations of its tags.
+type countingValidation struct {
+	compiled *int
+}
+
+func (c countingValidation) Validate(v interface{}, args ...string) error {
+	return validateCompiled(c, v, args)
+}
+
+func (c countingValidation) Compile(args ...string) (Validator, error) {
+	*c.compiled++
+	return MinValidation{}.Compile(args...)
+}
+
+func TestValidatePlanCache(t *testing.T) {
+	type Item struct {
+		N int `validate:"count:1"`
+	}
+	type Order struct {
+		Items []Item `validate:"nested"`
+		Total int    `validate:"count:0"`
+	}
+
+	compiled := 0
+	v := NewValidator()
+	v.Register("count", countingValidation{compiled: &compiled})
+	order := Order{Items: []Item{{N: 1}, {N: 0}, {N: 2}}, Total: 3}
+	for i := 0; i < 3; i++ {
+		var errs ValidationErrors
+		require.ErrorAs(t, v.Validate(order), &errs)
+		require.Len(t, errs, 1)
+		require.Equal(t, "Items[1].N", errs[0].Field)
+	}
+	require.Equal(t, 2, compiled, "tags are compiled once per type")
+
+	// plans are compiled again with replaced validator
+	v.Register("count", countingValidation{compiled: &compiled})
+	require.Error(t, v.Validate(order))
+	require.Equal(t, 4, compiled)
+}
+
+func TestValidatePlanError(t *testing.T) {
+	type Bad struct {
+		Name string `validate:"len:5"`
+		Code string `validate:"regexp:["`
+	}
+
+	v := NewValidator()
+	for i := 0; i < 2; i++ {
+		// malformed tag is reported even if value is valid
+		err := v.Validate(Bad{Name: "12345", Code: "x"})
+		var pe ParseValidatorError
+		require.ErrorAs(t, err, &pe)
+		require.ErrorIs(t, err, ErrInvalidParameter)
+		require.Equal(t, "regexp:[", pe.Tag)
+	}
+}
+
+func TestValidatePlanErrorNotCalled(t *testing.T) {
+	// validators are not called for nil pointers and empty slices, but their tags are checked
+	tests := []struct {
+		data interface{}
+		err  error
+		tag  string
+	}{
+		{struct {
+			IP *string `validate:"ip:v5"`
+		}{}, ErrInvalidParameter, "ip:v5"},
+		{struct {
+			E *string `validate:"email:foo"`
+		}{}, ErrUnexpectedParameter, "email:foo"},
+		{struct {
+			U []string `validate:"uuid:4"`
+		}{}, ErrUnexpectedParameter, "uuid:4"},
+		{struct {
+			U []string `validate:"url:1http"`
+		}{}, ErrInvalidParameter, "url:1http"},
+		{struct {
+			D *string `validate:"datetime"`
+		}{}, ErrExpectedOneParameter, "datetime"},
+		{struct {
+			N *int `validate:"nonzero:1"`
+		}{N: new(int)}, ErrUnexpectedParameter, "nonzero:1"},
+		{struct {
+			A *int `validate:"gtfield:Missing"`
+		}{}, ErrFieldDoesNotExist, "gtfield:Missing"},
+		{struct {
+			A *int `validate:"eqfield:B.Missing"`
+			B *struct{ C int }
+		}{}, ErrFieldDoesNotExist, "eqfield:B.Missing"},
+		{struct {
+			A []int `validate:"ltfield:B|ltfield"`
+			B int
+		}{}, ErrExpectedOneParameter, "ltfield"},
+		{struct {
+			A *int `validate:"required_if:B,1,C"`
+			B int
+		}{}, ErrInvalidParameter, "required_if:B,1,C"},
+		{struct {
+			A *int `validate:"required_if:B,1,Missing,2"`
+			B int
+		}{}, ErrFieldDoesNotExist, "required_if:B,1,Missing,2"},
+	}
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("test case: #%d", i), func(t *testing.T) {
+			err := NewValidator().Validate(tc.data)
+			var pe ParseValidatorError
+			require.ErrorAs(t, err, &pe)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.tag, pe.Tag)
+		})
+	}
+
+	// fields of interfaces are known at run time only
+	err := Validate(struct {
+		A int `validate:"eqfield:B.C"`
+		B interface{}
+	}{})
+	require.NoError(t, err)
+}
+
+func TestValidateConcurrent(t *testing.T) {
+	type User struct {
+		Name string `validate:"len:3"`
+		Age  int    `validate:"min:18|max:50"`
+	}
+
+	v := NewValidator()
+	errs := make(chan error, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%4 == 0 {
+				v.Register("even", evenValidation{})
+			}
+			errs <- v.Validate(User{Name: "Bob", Age: 20 + i})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}